@@ -15,8 +15,10 @@
 package apiware
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"reflect"
 
 	"github.com/valyala/fasthttp"
 )
@@ -26,6 +28,8 @@ type (
 		ParamNameFunc
 		PathDecodeFunc
 		BodyDecodeFunc
+		// the registration options, e.g. `StrictTags()`
+		Options []Option
 	}
 
 	// Parse path params function, return pathParams of KV type
@@ -44,12 +48,27 @@ func New(pathDecodeFunc PathDecodeFunc, bodyDecodeFunc BodyDecodeFunc, paramName
 	}
 }
 
+// Create a new apiware engine which decodes the XML body, see `BodyXML`.
+// if `paramNameFunc` is nil, `paramNameFunc=toSnake`,
+func NewWithXMLBody(pathDecodeFunc PathDecodeFunc, paramNameFunc ParamNameFunc) *Apiware {
+	return New(pathDecodeFunc, BodyXML, paramNameFunc)
+}
+
+// Create a new apiware engine which picks the body decoder from mux by the request's Content-Type,
+// and decodes the JSON body when the media type is not in mux.
+// if `paramNameFunc` is nil, `paramNameFunc=toSnake`,
+func NewWithBodyMux(pathDecodeFunc PathDecodeFunc, mux BodyDecoderMux, paramNameFunc ParamNameFunc) *Apiware {
+	a := New(pathDecodeFunc, nil, paramNameFunc)
+	a.Options = append(a.Options, BodyMux(mux))
+	return a
+}
+
 // Check whether structs meet the requirements of apiware, and register them.
 // note: requires a structure pointer.
 func (a *Apiware) Register(structPointers ...interface{}) error {
 	var errStr string
 	for _, obj := range structPointers {
-		err := Register(obj, a.ParamNameFunc, a.BodyDecodeFunc)
+		err := Register(obj, a.ParamNameFunc, a.BodyDecodeFunc, a.Options...)
 		if err != nil {
 			errStr += err.Error() + "\n"
 		}
@@ -60,6 +79,12 @@ func (a *Apiware) Register(structPointers ...interface{}) error {
 	return nil
 }
 
+// MustRegister registers the struct and returns its `*ParamsAPI`, panics if it does not meet the requirements of apiware.
+// note: requires a structure pointer.
+func (a *Apiware) MustRegister(structPointer interface{}) *ParamsAPI {
+	return MustRegister(structPointer, a.ParamNameFunc, a.BodyDecodeFunc, a.Options...)
+}
+
 // Bind the net/http request params to the structure and validate.
 // note: structPointer must be structure pointer.
 func (a *Apiware) Bind(
@@ -75,3 +100,24 @@ func (a *Apiware) Bind(
 func (a *Apiware) FasthttpBind(structPointer interface{}, reqCtx *fasthttp.RequestCtx, pattern string) (err error) {
 	return FasthttpBind(structPointer, reqCtx, a.PathDecodeFunc(string(reqCtx.Path()), pattern))
 }
+
+// BindAndRespond binds the net/http request params to the structure and validate,
+// if it fails, writes the error as JSON with the status code 400 and returns false,
+// the validation error is localized by the `Accept-Language` header, see `SetMessages`.
+// note: structPointer must be structure pointer.
+func (a *Apiware) BindAndRespond(
+	resp http.ResponseWriter,
+	req *http.Request,
+	structPointer interface{},
+	pattern string,
+) bool {
+	err := a.Bind(structPointer, req, pattern)
+	if err == nil {
+		return true
+	}
+	b, _ := json.Marshal(toError(reflect.TypeOf(structPointer).String(), err, RequestLang(req)))
+	resp.Header().Set("Content-Type", "application/json; charset=utf-8")
+	resp.WriteHeader(http.StatusBadRequest)
+	resp.Write(b)
+	return false
+}
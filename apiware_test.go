@@ -0,0 +1,184 @@
+package apiware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testPathDecodeFunc(urlPath, pattern string) KV {
+	return Map(map[string]string{})
+}
+
+func TestNewWithXMLBody(t *testing.T) {
+	type order struct {
+		ID    int      `xml:"id,attr"`
+		Items []string `xml:"item"`
+	}
+	type xmlParams struct {
+		Order order `param:"in(body)"`
+	}
+	a := NewWithXMLBody(testPathDecodeFunc, nil)
+	if err := a.Register(new(xmlParams)); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/order", strings.NewReader(`<order id="7"><item>a</item><item>b</item></order>`))
+	params := new(xmlParams)
+	if err := a.Bind(params, req, "/order"); err != nil {
+		t.Fatal(err)
+	}
+	if params.Order.ID != 7 || len(params.Order.Items) != 2 || params.Order.Items[1] != "b" {
+		t.Fatalf("wrong value: %#v", params.Order)
+	}
+	req = httptest.NewRequest("POST", "/order", strings.NewReader(`<order id="x">`))
+	if err := a.Bind(new(xmlParams), req, "/order"); err == nil {
+		t.Fatal("should not bind")
+	}
+}
+
+func TestNewWithBodyMux(t *testing.T) {
+	type muxOrder struct {
+		ID int `json:"id" xml:"id,attr"`
+	}
+	type muxParams struct {
+		Order muxOrder `param:"in(body)"`
+	}
+	a := NewWithBodyMux(testPathDecodeFunc, BodyDecoderMux{
+		"application/xml": BodyXML,
+		"text/xml":        BodyXML,
+	}, nil)
+	if err := a.Register(new(muxParams)); err != nil {
+		t.Fatal(err)
+	}
+	for contentType, body := range map[string]string{
+		"application/xml; charset=utf-8": `<order id="7"></order>`,
+		"TEXT/XML":                       `<order id="7"></order>`,
+		"application/json":               `{"id":7}`,
+		"":                               `{"id":7}`,
+	} {
+		req := httptest.NewRequest("POST", "/order", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		params := new(muxParams)
+		if err := a.Bind(params, req, "/order"); err != nil {
+			t.Fatalf("%q: %v", contentType, err)
+		}
+		if params.Order.ID != 7 {
+			t.Fatalf("%q: wrong value %d", contentType, params.Order.ID)
+		}
+	}
+}
+
+func TestBindAndRespond(t *testing.T) {
+	type respondParams struct {
+		Id int `param:"in(query),required,range(1:9)"`
+	}
+	a := New(testPathDecodeFunc, nil, nil)
+	if err := a.Register(new(respondParams)); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/respond?id=3", nil)
+	params := new(respondParams)
+	if !a.BindAndRespond(resp, req, params, "/respond") {
+		t.Fatal("should bind", resp.Body.String())
+	}
+	if params.Id != 3 {
+		t.Fatal("wrong value", params.Id)
+	}
+
+	for query, reason := range map[string]string{
+		"":       "missing query param",
+		"?id=x":  `converting type []string ("x") to a int: invalid syntax`,
+		"?id=10": "id too big",
+	} {
+		resp = httptest.NewRecorder()
+		req = httptest.NewRequest("GET", "/respond"+query, nil)
+		if a.BindAndRespond(resp, req, new(respondParams), "/respond") {
+			t.Fatal("should not bind", query)
+		}
+		if resp.Code != http.StatusBadRequest {
+			t.Fatal("wrong status code", resp.Code)
+		}
+		if ct := resp.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Fatal("wrong content type", ct)
+		}
+		var e Error
+		if err := json.Unmarshal(resp.Body.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		if e.Api != "*apiware.respondParams" || e.Param != "id" || e.Reason != reason {
+			t.Fatalf("wrong error body: %s", resp.Body.String())
+		}
+	}
+
+	// the non-validation error, e.g. of the unregistered struct, is 400 too
+	type unregisteredParams struct {
+		Id int `param:"in(query)"`
+	}
+	resp = httptest.NewRecorder()
+	if a.BindAndRespond(resp, httptest.NewRequest("GET", "/respond", nil), new(unregisteredParams), "/respond") {
+		t.Fatal("should not bind the unregistered struct")
+	}
+	if resp.Code != http.StatusBadRequest {
+		t.Fatal("wrong status code", resp.Code)
+	}
+}
+
+func TestMustRegister(t *testing.T) {
+	type mustParams struct {
+		Name string `param:"in(query),required"`
+	}
+	a := New(testPathDecodeFunc, nil, nil)
+	m := a.MustRegister(new(mustParams))
+	if m.Number() != 1 || m.Params()[0].Name() != "name" {
+		t.Fatalf("wrong params api: %#v", m.Params())
+	}
+
+	type badMustParams struct {
+		Name string `param:"in(nowhere)"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic for the invalid struct")
+		}
+	}()
+	a.MustRegister(new(badMustParams))
+}
+
+func TestStatusCode(t *testing.T) {
+	type statusParams struct {
+		ID int `param:"in(query),name(id),required,range(1:9)"`
+	}
+	m, err := NewParamsAPI(new(statusParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, code := range map[string]int{
+		"?id=3":  http.StatusOK,
+		"":       http.StatusBadRequest,
+		"?id=x":  http.StatusBadRequest,
+		"?id=10": http.StatusBadRequest,
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", "/"+query, nil), nil)
+		if c := StatusCode(err); c != code {
+			t.Fatal("wrong status code", query, c, err)
+		}
+	}
+
+	type badStatusParams struct {
+		ID int `param:"in(nowhere)"`
+	}
+	_, err = NewParamsAPI(new(badStatusParams), nil, nil)
+	if c := StatusCode(err); c != http.StatusInternalServerError {
+		t.Fatal("wrong status code for the registration error", c, err)
+	}
+	if c := StatusCode(Bind(new(struct{ ID int }), httptest.NewRequest("GET", "/", nil), nil)); c != http.StatusInternalServerError {
+		t.Fatal("wrong status code for the unregistered struct", c)
+	}
+	if c := StatusCode(MultiError{NewValidationError(ValidationErrorValueNotSet, "id"), err}); c != http.StatusInternalServerError {
+		t.Fatal("wrong status code for the multiple errors", c)
+	}
+}
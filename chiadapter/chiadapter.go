@@ -0,0 +1,43 @@
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chiadapter feeds the path params parsed by go-chi/chi to apiware,
+// it is a separate package so that apiware does not depend on chi.
+//
+//	err := apiware.Bind(params, req, chiadapter.ChiURLParams(req))
+package chiadapter
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/henrylee2cn/apiware"
+)
+
+// ChiURLParams returns the path params of the request routed by chi,
+// it implements `apiware.KeysKV`, so that the catch-all `map[string]string` path param works too.
+// note: the wildcard `*` is kept as the `*` param.
+func ChiURLParams(req *http.Request) apiware.KV {
+	m := apiware.Map{}
+	rctx := chi.RouteContext(req.Context())
+	if rctx == nil {
+		return m
+	}
+	for i, k := range rctx.URLParams.Keys {
+		if i < len(rctx.URLParams.Values) {
+			m[k] = rctx.URLParams.Values[i]
+		}
+	}
+	return m
+}
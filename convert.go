@@ -15,10 +15,20 @@
 package apiware
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Type conversions for request params.
@@ -26,15 +36,31 @@ import (
 // ConvertAssign copies to dest the value in src, converting it if possible.
 // An error is returned if the copy would result in loss of information.
 // dest should be a pointer type.
+// The precedence is: the converter registered by `RegisterConverter` for dest's type first,
+// then `Set(string) error` (like `flag.Value`) if dest implements it, which is called with each value in src,
+// and the built-in types last.
 func ConvertAssign(dest reflect.Value, src ...string) (err error) {
-	return convertAssign(dest, src)
+	return convertAssign(context.Background(), dest, src)
 }
 
-func convertAssign(dest reflect.Value, src []string) (err error) {
+// convertAssign is like ConvertAssign, ctx is passed to the converter registered by `RegisterConverterContext`.
+func convertAssign(ctx context.Context, dest reflect.Value, src []string) error {
+	return convertAssignWith(ctx, dest, src, resolveAssign)
+}
+
+// assignFunc assigns src to the settable dest of the type it is resolved for.
+type assignFunc func(ctx context.Context, dest reflect.Value, src []string) error
+
+// convertAssignWith is like convertAssign, but the assigner of dest's type is given by resolve.
+func convertAssignWith(ctx context.Context, dest reflect.Value, src []string, resolve func(reflect.Type) assignFunc) (err error) {
 	if len(src) == 0 {
 		return nil
 	}
 
+	// allocate the optional base type, e.g. `*int`
+	if dest.Kind() == reflect.Ptr && dest.IsNil() && dest.CanSet() {
+		dest.Set(reflect.New(dest.Type().Elem()))
+	}
 	dest = reflect.Indirect(dest)
 	if !dest.CanSet() {
 		return fmt.Errorf("%s can not be setted", dest.Type().Name())
@@ -46,6 +72,33 @@ func convertAssign(dest reflect.Value, src []string) (err error) {
 		}
 	}()
 
+	return resolve(dest.Type())(ctx, dest, src)
+}
+
+// resolveAssign returns the assigner of the type t, in the precedence of ConvertAssign.
+func resolveAssign(t reflect.Type) assignFunc {
+	if fn, ok := lookupConverter(t); ok {
+		return fn
+	}
+	if reflect.PtrTo(t).Implements(stringSetterType) {
+		return assignSetter
+	}
+	return assignBuiltin
+}
+
+// assignSetter calls `Set(string) error` of dest with each value in src.
+func assignSetter(_ context.Context, dest reflect.Value, src []string) error {
+	setter := dest.Addr().Interface().(stringSetter)
+	for _, s := range src {
+		if err := setter.Set(s); err != nil {
+			return fmt.Errorf("converting type %T (%q) to a %s: %v", src, s, dest.Type(), err)
+		}
+	}
+	return nil
+}
+
+// assignBuiltin assigns src to dest of the built-in types.
+func assignBuiltin(ctx context.Context, dest reflect.Value, src []string) (err error) {
 	switch dest.Interface().(type) {
 	case string:
 		dest.Set(reflect.ValueOf(src[0]))
@@ -78,9 +131,60 @@ func convertAssign(dest reflect.Value, src []string) (err error) {
 		}
 		dest.Set(reflect.ValueOf(b))
 		return nil
+
+	case time.Time, []time.Time:
+		return convertTime(dest, src, time.RFC3339)
+
+	case time.Duration:
+		d, err := time.ParseDuration(src[0])
+		if err != nil {
+			return fmt.Errorf("converting type %T (%q) to a time.Duration: %v", src, src[0], err)
+		}
+		dest.Set(reflect.ValueOf(d))
+		return nil
+
+	case []time.Duration:
+		ds := make([]time.Duration, 0, len(src))
+		for i, s := range src {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return fmt.Errorf("converting type %T (%q) at index %d to a time.Duration: %v", src, s, i, err)
+			}
+			ds = append(ds, d)
+		}
+		dest.Set(reflect.ValueOf(ds))
+		return nil
+
+	case net.IP:
+		ip := net.ParseIP(src[0])
+		if ip == nil {
+			return fmt.Errorf("converting type %T (%q) to a net.IP: invalid IP address", src, src[0])
+		}
+		dest.Set(reflect.ValueOf(ip))
+		return nil
+
+	case net.HardwareAddr:
+		mac, err := net.ParseMAC(src[0])
+		if err != nil {
+			return fmt.Errorf("converting type %T (%q) to a net.HardwareAddr: %v", src, src[0], err)
+		}
+		dest.Set(reflect.ValueOf(mac))
+		return nil
+
+	case net.IPNet:
+		_, ipNet, err := net.ParseCIDR(src[0])
+		if err != nil {
+			return fmt.Errorf("converting type %T (%q) to a net.IPNet: %v", src, src[0], err)
+		}
+		dest.Set(reflect.ValueOf(*ipNet))
+		return nil
 	}
 
 	switch dest.Kind() {
+	case reflect.String:
+		dest.SetString(src[0])
+		return nil
+
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		i64, err := strconv.ParseInt(src[0], 10, dest.Type().Bits())
 		if err != nil {
@@ -108,9 +212,38 @@ func convertAssign(dest reflect.Value, src []string) (err error) {
 		dest.SetFloat(f64)
 		return nil
 
+	case reflect.Complex64, reflect.Complex128:
+		c128, err := strconv.ParseComplex(src[0], dest.Type().Bits())
+		if err != nil {
+			err = strconvErr(err)
+			return fmt.Errorf("converting type %T (%q) to a %s: %v", src, src[0], dest.Kind(), err)
+		}
+		dest.SetComplex(c128)
+		return nil
+
+	case reflect.Array:
+		// the UUID of 16 bytes, e.g. `uuid.UUID`
+		if dest.Len() != 16 || dest.Type().Elem().Kind() != reflect.Uint8 {
+			break
+		}
+		u, ok := parseUUID(src[0])
+		if !ok {
+			return fmt.Errorf("converting type %T (%q) to a %s: invalid uuid", src, src[0], dest.Type())
+		}
+		for i, b := range u {
+			dest.Index(i).SetUint(uint64(b))
+		}
+		return nil
+
 	case reflect.Slice:
 		member := dest.Type().Elem()
 		switch member.Kind() {
+		case reflect.String:
+			for _, s := range src {
+				dest.Set(reflect.Append(dest, reflect.ValueOf(s).Convert(member)))
+			}
+			return nil
+
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			for _, s := range src {
 				i64, err := strconv.ParseInt(s, 10, member.Bits())
@@ -143,20 +276,418 @@ func convertAssign(dest reflect.Value, src []string) (err error) {
 				dest.Set(reflect.Append(dest, reflect.ValueOf(f64).Convert(member)))
 			}
 			return nil
+
+		case reflect.Complex64, reflect.Complex128:
+			for _, s := range src {
+				c128, err := strconv.ParseComplex(s, member.Bits())
+				if err != nil {
+					err = strconvErr(err)
+					return fmt.Errorf("converting type %T (%q) to a %s: %v", src, s, dest.Kind(), err)
+				}
+				dest.Set(reflect.Append(dest, reflect.ValueOf(c128).Convert(member)))
+			}
+			return nil
+
+		case reflect.Array:
+			for _, s := range src {
+				elem := reflect.New(member).Elem()
+				if err = convertAssign(ctx, elem, []string{s}); err != nil {
+					return err
+				}
+				dest.Set(reflect.Append(dest, elem))
+			}
+			return nil
+
+		case reflect.Struct:
+			// each value is parsed into an element by the converter registered for it, `Set(string) error`, or JSON
+			for _, s := range src {
+				elem := reflect.New(member).Elem()
+				_, ok := lookupConverter(member)
+				if _, isSetter := elem.Addr().Interface().(stringSetter); ok || isSetter {
+					err = convertAssign(ctx, elem, []string{s})
+				} else if err = json.Unmarshal([]byte(s), elem.Addr().Interface()); err != nil {
+					err = fmt.Errorf("converting type %T (%q) to a %s: %v", src, s, member, err)
+				}
+				if err != nil {
+					return err
+				}
+				dest.Set(reflect.Append(dest, elem))
+			}
+			return nil
 		}
 	}
 
 	return fmt.Errorf("unsupported storing type %T into type %s", src, dest.Kind())
 }
 
-func parseBool(val string) bool {
-	switch strings.TrimSpace(strings.ToLower(val)) {
-	case "true", "on", "1":
+var (
+	// words parsed as `true` for bool params
+	truthyWords = map[string]bool{
+		"true": true,
+		"on":   true,
+		"1":    true,
+	}
+	// words parsed as `false` for bool params, the other non-truthy words are invalid for `LenientBool`
+	falsyWords = map[string]bool{
+		"false": true,
+		"off":   true,
+		"0":     true,
+		"no":    true,
+	}
+	boolWordsLock sync.RWMutex
+)
+
+// AddTruthyWords adds words which are parsed as `true` for bool params,
+// e.g. localized affirmatives like `sí` or `oui`. The comparison ignores case.
+func AddTruthyWords(words ...string) {
+	boolWordsLock.Lock()
+	defer boolWordsLock.Unlock()
+	for _, w := range words {
+		truthyWords[strings.TrimSpace(strings.ToLower(w))] = true
+	}
+}
+
+// AddFalsyWords adds words which are parsed as `false` for bool params,
+// e.g. localized negatives like `nein` or `non`, so that `LenientBool` does not treat them as invalid.
+// The comparison ignores case.
+func AddFalsyWords(words ...string) {
+	boolWordsLock.Lock()
+	defer boolWordsLock.Unlock()
+	for _, w := range words {
+		falsyWords[strings.TrimSpace(strings.ToLower(w))] = true
+	}
+}
+
+// SortField is an item of the sort param, e.g. `sort=name,-age` is bound as
+// `[]SortField{{Field: "name"}, {Field: "age", Desc: true}}`.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+var sortFieldsType = reflect.TypeOf([]SortField{})
+
+// convertSortFields parses the comma separated sort fields, and checks them against the whitelist.
+func convertSortFields(dest reflect.Value, src []string, whitelist []string) error {
+	var fields []SortField
+	for _, s := range src {
+		for _, f := range strings.Split(s, ",") {
+			f = strings.TrimSpace(f)
+			if len(f) == 0 {
+				continue
+			}
+			sf := SortField{Field: f}
+			if f[0] == '-' {
+				sf.Field, sf.Desc = f[1:], true
+			}
+			var allowed bool
+			for _, w := range whitelist {
+				if sf.Field == strings.TrimSpace(w) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("sort field `%s` is not allowed", sf.Field)
+			}
+			fields = append(fields, sf)
+		}
+	}
+	reflect.Indirect(dest).Set(reflect.ValueOf(fields))
+	return nil
+}
+
+var bytesType = reflect.TypeOf([]byte{})
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	timesType     = reflect.TypeOf([]time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	durationsType = reflect.TypeOf([]time.Duration{})
+)
+
+// convertTime parses src with the layout, and assigns it to the `time.Time` or `[]time.Time` dest.
+func convertTime(dest reflect.Value, src []string, layout string) error {
+	if len(src) == 0 {
+		return nil
+	}
+	dest = reflect.Indirect(dest)
+	if dest.Type() == timeType {
+		t, err := time.Parse(layout, src[0])
+		if err != nil {
+			return fmt.Errorf("converting type %T (%q) to a time.Time: %v", src, src[0], err)
+		}
+		dest.Set(reflect.ValueOf(t))
+		return nil
+	}
+	ts := make([]time.Time, 0, len(src))
+	for i, s := range src {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return fmt.Errorf("converting type %T (%q) at index %d to a time.Time: %v", src, s, i, err)
+		}
+		ts = append(ts, t)
+	}
+	dest.Set(reflect.ValueOf(ts))
+	return nil
+}
+
+// convertEndian decodes each hex or base64 value in src into the bytes of the fixed-width integer dest,
+// interpreting them with the byte order, and the values shorter than dest are zero-extended.
+func convertEndian(dest reflect.Value, src []string, order binary.ByteOrder) error {
+	if len(src) == 0 {
+		return nil
+	}
+	for dest.Kind() == reflect.Ptr {
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+	if dest.Kind() != reflect.Slice {
+		return setEndianInt(dest, src[0], order)
+	}
+	s := reflect.MakeSlice(dest.Type(), len(src), len(src))
+	for i, v := range src {
+		if err := setEndianInt(s.Index(i), v, order); err != nil {
+			return fmt.Errorf("%v at index %d", err, i)
+		}
+	}
+	dest.Set(s)
+	return nil
+}
+
+func setEndianInt(dest reflect.Value, s string, order binary.ByteOrder) error {
+	b, err := decodeBinary(s)
+	if err != nil {
+		return fmt.Errorf("converting %q to a %s: %v", s, dest.Type(), err)
+	}
+	width := int(dest.Type().Size())
+	if len(b) > width {
+		return fmt.Errorf("converting %q to a %s: %d bytes overflow the %d-byte integer", s, dest.Type(), len(b), width)
+	}
+	buf := make([]byte, 8)
+	if order == binary.BigEndian {
+		copy(buf[8-len(b):], b)
+	} else {
+		copy(buf, b)
+	}
+	u := order.Uint64(buf)
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// sign-extend from the field width
+		shift := uint(64 - 8*width)
+		dest.SetInt(int64(u<<shift) >> shift)
+	default:
+		dest.SetUint(u)
+	}
+	return nil
+}
+
+// decodeBinary decodes s as hex (with an optional "0x" prefix) first, then as standard or URL base64.
+func decodeBinary(s string) ([]byte, error) {
+	if b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")); err == nil {
+		return b, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, errors.New("invalid hex or base64 bytes")
+}
+
+var (
+	converters     = map[reflect.Type]func(ctx context.Context, dst reflect.Value, raw []string) error{}
+	convertersLock sync.RWMutex
+	// convertersVersion is increased by each registration of the converters,
+	// so that the assigners prepared by `WarmUp` are resolved again.
+	convertersVersion uint64
+)
+
+var (
+	transforms = map[string]func(string) (string, error){
+		"trim": func(s string) (string, error) {
+			return strings.TrimSpace(s), nil
+		},
+		"lower": func(s string) (string, error) {
+			return strings.ToLower(s), nil
+		},
+		"upper": func(s string) (string, error) {
+			return strings.ToUpper(s), nil
+		},
+		"base64": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+		"base64url": func(s string) (string, error) {
+			b, err := base64.URLEncoding.DecodeString(s)
+			return string(b), err
+		},
+	}
+	transformsLock sync.RWMutex
+)
+
+// RegisterTransform registers the named function for the `parsers` tag,
+// which transforms each raw request value in order before the conversion, e.g. `parsers(trim|base64)`.
+// The built-in transforms are `trim`, `lower`, `upper`, `base64` and `base64url`, the last two decode the value,
+// and the first three also back the `trim`, `lower` and `upper` tags, so overriding them changes those tags too.
+// The transforms are looked up when registering the ParamsAPI, and it is removed when fn is nil.
+func RegisterTransform(name string, fn func(string) (string, error)) {
+	transformsLock.Lock()
+	defer transformsLock.Unlock()
+	if fn == nil {
+		delete(transforms, name)
+		return
+	}
+	transforms[name] = fn
+}
+
+// parseTransforms looks up the transforms of the `parsers` tag value.
+func parseTransforms(list string) ([]func(string) (string, error), error) {
+	transformsLock.RLock()
+	defer transformsLock.RUnlock()
+	var chain []func(string) (string, error)
+	for _, name := range strings.Split(list, "|") {
+		fn, ok := transforms[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform `%s`", name)
+		}
+		chain = append(chain, fn)
+	}
+	return chain, nil
+}
+
+// applyTransforms runs the chain on each value in src in order.
+func applyTransforms(chain []func(string) (string, error), src []string) ([]string, error) {
+	values := make([]string, len(src))
+	for i, s := range src {
+		for _, fn := range chain {
+			var err error
+			if s, err = fn(s); err != nil {
+				return nil, err
+			}
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+// RegisterConverter registers the function to convert the request values to the type t,
+// e.g. a `decimal.Decimal` or `type UserID uuid.UUID`. dst is the settable value of type t,
+// and raw are the request values of the param, which is never empty.
+// The registered converter takes precedence over `Set(string) error` and the built-in types,
+// it is removed when fn is nil.
+func RegisterConverter(t reflect.Type, fn func(dst reflect.Value, raw []string) error) {
+	if fn == nil {
+		RegisterConverterContext(t, nil)
+		return
+	}
+	RegisterConverterContext(t, func(_ context.Context, dst reflect.Value, raw []string) error {
+		return fn(dst, raw)
+	})
+}
+
+// RegisterConverterContext is like RegisterConverter, but fn also receives the context of the binding,
+// e.g. to look up the values in a database with the request's deadline. It is the request's context for
+// the net/http bindings, ctx of `BindFieldsContext`, and `context.Background()` for the fasthttp bindings.
+func RegisterConverterContext(t reflect.Type, fn func(ctx context.Context, dst reflect.Value, raw []string) error) {
+	convertersLock.Lock()
+	defer convertersLock.Unlock()
+	atomic.AddUint64(&convertersVersion, 1)
+	if fn == nil {
+		delete(converters, t)
+		return
+	}
+	converters[t] = fn
+}
+
+// RegisterEnumNames registers the names of the integer enum type t, e.g. `?status=active` is bound as `Status(1)`,
+// and the slice of t is supported too. The unknown name fails the binding.
+// It is a converter registered by `RegisterConverter`, and it panics if t is not an integer type.
+func RegisterEnumNames(t reflect.Type, names map[string]int64) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	default:
+		panic("apiware: the enum type `" + t.String() + "` is not an integer type")
+	}
+	m := make(map[string]int64, len(names))
+	for k, v := range names {
+		m[k] = v
+	}
+	lookup := func(name string) (reflect.Value, error) {
+		i, ok := m[name]
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("unknown %s name %q", t, name)
+		}
+		return reflect.ValueOf(i).Convert(t), nil
+	}
+	RegisterConverter(t, func(dst reflect.Value, raw []string) error {
+		v, err := lookup(raw[0])
+		if err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	})
+	RegisterConverter(reflect.SliceOf(t), func(dst reflect.Value, raw []string) error {
+		for _, name := range raw {
+			v, err := lookup(name)
+			if err != nil {
+				return err
+			}
+			dst.Set(reflect.Append(dst, v))
+		}
+		return nil
+	})
+}
+
+func lookupConverter(t reflect.Type) (func(ctx context.Context, dst reflect.Value, raw []string) error, bool) {
+	convertersLock.RLock()
+	defer convertersLock.RUnlock()
+	fn, ok := converters[t]
+	return fn, ok
+}
+
+// isBaseKind tests if the kind is a string, bool, integer or float.
+func isBaseKind(kind reflect.Kind) bool {
+	return kind == reflect.String || kind == reflect.Bool || isNumberKind(kind) ||
+		kind == reflect.Complex64 || kind == reflect.Complex128
+}
+
+// isNumberKind tests if the kind is an integer or a float.
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
 		return true
 	}
 	return false
 }
 
+// stringSetter is implemented by the types which can parse themselves, e.g. `flag.Value`.
+type stringSetter interface {
+	Set(string) error
+}
+
+var stringSetterType = reflect.TypeOf((*stringSetter)(nil)).Elem()
+
+// isBoolWord tests if val is a truthy or falsy word.
+func isBoolWord(val string) bool {
+	val = strings.TrimSpace(strings.ToLower(val))
+	boolWordsLock.RLock()
+	defer boolWordsLock.RUnlock()
+	return falsyWords[val] || truthyWords[val]
+}
+
+func parseBool(val string) bool {
+	boolWordsLock.RLock()
+	defer boolWordsLock.RUnlock()
+	return truthyWords[strings.TrimSpace(strings.ToLower(val))]
+}
+
 func strconvErr(err error) error {
 	if ne, ok := err.(*strconv.NumError); ok {
 		return ne.Err
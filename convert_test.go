@@ -0,0 +1,247 @@
+package apiware
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConvertNetTypes(t *testing.T) {
+	var mac net.HardwareAddr
+	if err := ConvertAssign(reflect.ValueOf(&mac), "00:1a:2b:3c:4d:5e"); err != nil {
+		t.Fatal(err)
+	}
+	if mac.String() != "00:1a:2b:3c:4d:5e" {
+		t.Fatal("wrong value", mac)
+	}
+	if err := ConvertAssign(reflect.ValueOf(&mac), "00:1a:2b:3c:4d"); err == nil {
+		t.Fatal("should not convert invalid MAC")
+	}
+
+	var ipNet net.IPNet
+	if err := ConvertAssign(reflect.ValueOf(&ipNet), "192.168.1.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if ipNet.String() != "192.168.1.0/24" {
+		t.Fatal("wrong value", ipNet.String())
+	}
+	if err := ConvertAssign(reflect.ValueOf(&ipNet), "192.168.1.0/33"); err == nil {
+		t.Fatal("should not convert invalid CIDR")
+	}
+
+	var ip net.IP
+	if err := ConvertAssign(reflect.ValueOf(&ip), "::1"); err != nil || !ip.Equal(net.IPv6loopback) {
+		t.Fatal("wrong value", ip, err)
+	}
+	if err := ConvertAssign(reflect.ValueOf(&ip), "1.2.3"); err == nil {
+		t.Fatal("should not convert invalid IP")
+	}
+}
+
+// levelsValue is a flag.Value-style type
+type levelsValue []string
+
+var _ flag.Value = new(levelsValue)
+
+func (l *levelsValue) String() string { return strings.Join(*l, ",") }
+
+func (l *levelsValue) Set(s string) error {
+	switch s {
+	case "debug", "info", "warn":
+		*l = append(*l, s)
+		return nil
+	}
+	return errors.New("unknown level")
+}
+
+func TestConvertSetter(t *testing.T) {
+	type setterParams struct {
+		Level levelsValue `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(setterParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?level=debug&level=warn", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := v.(*setterParams).Level.String(); l != "debug,warn" {
+		t.Fatal("wrong value", l)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?level=trace", nil), nil)
+	if e, ok := err.(*Error); !ok || !strings.HasSuffix(e.Reason, "unknown level") {
+		t.Fatal("should not bind", err)
+	}
+}
+
+func TestAddTruthyWords(t *testing.T) {
+	var b bool
+	if err := ConvertAssign(reflect.ValueOf(&b), "oui"); err != nil || b {
+		t.Fatal("wrong value", b, err)
+	}
+	AddTruthyWords("Sí", "oui")
+	for _, s := range []string{"oui", "OUI", "sí", "SÍ", "true"} {
+		b = false
+		if err := ConvertAssign(reflect.ValueOf(&b), s); err != nil || !b {
+			t.Fatal("should be true", s, err)
+		}
+	}
+	if err := ConvertAssign(reflect.ValueOf(&b), "non"); err != nil || b {
+		t.Fatal("should be false", b, err)
+	}
+}
+
+func TestAddFalsyWords(t *testing.T) {
+	if isBoolWord("nein") {
+		t.Fatal("should not be a bool word yet")
+	}
+	AddTruthyWords("ja")
+	AddFalsyWords("Nein")
+	type localizedParams struct {
+		Cache bool `param:"in(query),default(true)"`
+	}
+	m, err := NewParamsAPI(new(localizedParams), nil, nil, LenientBool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s, want := range map[string]bool{"nein": false, "NEIN": false, "ja": true, "vielleicht": true} {
+		v, err := m.BindNew(httptest.NewRequest("GET", "/?cache="+s, nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cache := v.(*localizedParams).Cache; cache != want {
+			t.Fatal("wrong value", s, cache)
+		}
+	}
+}
+
+type userID [2]uint32
+
+func TestRegisterConverter(t *testing.T) {
+	RegisterConverter(reflect.TypeOf(userID{}), func(dst reflect.Value, raw []string) error {
+		var id userID
+		if _, err := fmt.Sscanf(raw[0], "%d-%d", &id[0], &id[1]); err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(id))
+		return nil
+	})
+	defer RegisterConverter(reflect.TypeOf(userID{}), nil)
+
+	type converterParams struct {
+		ID    userID `param:"in(path),name(id)"`
+		Owner userID `param:"in(header),name(X-Owner)"`
+	}
+	m, err := NewParamsAPI(new(converterParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Owner", "3-4")
+	v, err := m.BindNew(req, Map{"id": "1-2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*converterParams); p.ID != (userID{1, 2}) || p.Owner != (userID{3, 4}) {
+		t.Fatal("wrong value", p.ID, p.Owner)
+	}
+	if _, err = m.BindNew(req, Map{"id": "x"}); err == nil {
+		t.Fatal("should not bind")
+	}
+
+	// the registered converter takes precedence over the built-in types
+	RegisterConverter(reflect.TypeOf(false), func(dst reflect.Value, raw []string) error {
+		dst.SetBool(raw[0] == "yes")
+		return nil
+	})
+	defer RegisterConverter(reflect.TypeOf(false), nil)
+	var b bool
+	if err = ConvertAssign(reflect.ValueOf(&b), "yes"); err != nil || !b {
+		t.Fatal("should use the registered converter", b, err)
+	}
+}
+
+func TestConvertComplex(t *testing.T) {
+	var c complex128
+	if err := ConvertAssign(reflect.ValueOf(&c), "1+2i"); err != nil || c != complex(1, 2) {
+		t.Fatal("wrong value", c, err)
+	}
+	var cs []complex64
+	if err := ConvertAssign(reflect.ValueOf(&cs), "3i", "-1.5"); err != nil || !reflect.DeepEqual(cs, []complex64{3i, -1.5}) {
+		t.Fatal("wrong value", cs, err)
+	}
+	for _, s := range []string{"1+", "i+1x", ""} {
+		if err := ConvertAssign(reflect.ValueOf(&c), s); err == nil {
+			t.Fatal("should not convert", s)
+		}
+	}
+
+	type complexParams struct {
+		Z    complex128  `param:"in(query),range(:5)"`
+		Opt  *complex64  `param:"in(query)"`
+		Many []complex64 `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(complexParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?z=3%2B4i&opt=1i&many=1&many=2i", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*complexParams); p.Z != complex(3, 4) || *p.Opt != 1i || len(p.Many) != 2 {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?z=4%2B4i", nil), nil); err == nil {
+		t.Fatal("should fail for the too large magnitude")
+	}
+
+	type badComplexParams struct {
+		Z complex128 `param:"in(query),positive"`
+	}
+	if _, err = NewParamsAPI(new(badComplexParams), nil, nil); err == nil {
+		t.Fatal("should fail for the sign tag")
+	}
+}
+
+type enumStatus int
+
+func TestRegisterEnumNames(t *testing.T) {
+	RegisterEnumNames(reflect.TypeOf(enumStatus(0)), map[string]int64{"inactive": 0, "active": 1, "banned": 2})
+	defer RegisterConverter(reflect.TypeOf(enumStatus(0)), nil)
+	defer RegisterConverter(reflect.TypeOf([]enumStatus{}), nil)
+
+	type enumParams struct {
+		Status enumStatus   `param:"in(query)"`
+		Filter []enumStatus `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(enumParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?status=active&filter=banned&filter=inactive", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*enumParams); p.Status != 1 || !reflect.DeepEqual(p.Filter, []enumStatus{2, 0}) {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	for _, query := range []string{"status=deleted", "status=1", "filter=active&filter=x"} {
+		if _, err = m.BindNew(httptest.NewRequest("GET", "/?"+query, nil), nil); err == nil {
+			t.Fatal("should fail for the unknown name", query)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("should panic for the non-integer type")
+		}
+	}()
+	RegisterEnumNames(reflect.TypeOf(""), nil)
+}
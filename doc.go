@@ -24,25 +24,90 @@ Param tag value description:
     param |    in    | only one |     body      | (position of param) request body can be any content
     param |    in    | only one |     header    | (position of param) request header info
     param |    in    | only one |     cookie    | (position of param) request cookie info, support: `http.Cookie`,`fasthttp.Cookie`,`string`,`[]byte`
+    param |    in    | only one |     host      | (position of param) request host, only for `string` field
+    param |    in    | only one |     scheme    | (position of param) request scheme(`http` or `https`), considering the `X-Forwarded-Proto` header, only for `string` field
+    param |    in    | only one |      auth     | (position of param) the `Authorization` header, only for `apiware.Authorization` field, Basic credentials are decoded into `User` and `Password`
+    param |    in    | only one |    bodysize   | (position of param) the number of the body bytes read during binding, e.g. for logging payload sizes, only for integer field
     param |   name   |    no    |  (e.g. "id")  | specify request param`s name
+    param |   skip   |    no    |      skip     | the whole tag `param:"skip"`(like `param:"-"`) ignores the field, while `name(-)` names the param `-`
     param | required |    no    |   required    | request param is required
     param |   desc   |    no    |  (e.g. "id")  | request param description
     param |   len    |    no    | (e.g. 3:6, 3) | length range of param's value
     param |   range  |    no    |  (e.g. 0:10)  | numerical range of param's value
     param |  nonzero |    no    |    nonzero    | param`s value can not be zero
-    param |   maxmb  |    no    |   (e.g. 32)   | when request Content-Type is multipart/form-data, the max memory for body.(multi-param, whichever is greater)
+    param |   maxmb  |    no    |   (e.g. 32)   | when request Content-Type is multipart/form-data, the max memory for body.(multi-param, whichever is greater, `BindOptions.MaxMemory` overrides it per request)
+    param |   oneof  |    no    |(e.g. "a|b|c") | the param's value(or each element) must be one of the listed values, for string or integer fields
+    param |    ci    |    no    |      ci       | `oneof` matches the value case-insensitively
+    param |   canon  |    no    |     canon     | when `ci` matches, rewrite the value to the listed casing
+    param |required_with|  no  |(e.g. "A,B")  | the param is required when any of the listed struct fields is present(non-zero)
+    param |sortfields|    no    |(e.g. "name,age")| whitelist of the `[]apiware.SortField` param, e.g. "sort=name,-age"
+    param | fallback |    no    |(e.g. "header:X-Token,query:token")| ordered sources to look up the param, binds from the first that supplies it, the name can be omitted
+    param | nonempty |    no    |    nonempty   | the uploaded file can not be empty
+    param |  base64  |    no    |     base64    | param's value must be valid standard base64, the padding can be omitted
+    param |base64url |    no    |   base64url   | param's value must be valid URL-safe base64, the padding can be omitted
+    param | latitude |    no    |    latitude   | numerical param's value must be within [-90,90]
+    param | longitude|    no    |   longitude   | numerical param's value must be within [-180,180]
+    param |novalidate|    no    |   novalidate  | bind the param but skip all of its validation
+    param |   time   |    no    |(e.g. "2006-01-02")| the layout to parse `time.Time` param, default RFC3339
+    param | sanitize |    no    |    sanitize   | run the sanitizer set by `SetSanitizer` on the string param after binding
+    param |   after  |    no    |(e.g. "2016-01-01")| `time.Time` param(or each element) must be after it, in the layout of `time`
+    param |  before  |    no    |(e.g. "2017-01-01")| `time.Time` param(or each element) must be before it, in the layout of `time`
+    param |    min   |    no    |   (e.g. "1")  | numerical param's value must be >= it
+    param |    max   |    no    |  (e.g. "100") | numerical param's value must be <= it
+    param |    gt    |    no    |   (e.g. "0")  | numerical param's value must be > it
+    param |    lt    |    no    |   (e.g. "1")  | numerical param's value must be < it
+    param |  default |    no    |   (e.g. "1")  | the value to assign when the param is absent or the no-value query `?name`, comma-separated for slices
+    param | hostname |    no    |    hostname   | param's value must be a valid hostname per the DNS rules
+    param |   fqdn   |    no    |      fqdn     | param's value must be a fully qualified domain name, e.g. `api.example.com`
+    param |  semver  |    no    |(e.g. ">=1.2.0 <2.0.0")| param's value must be a SemVer 2.0 version, satisfying the optional space separated constraints
+    param |required_methods| no |(e.g. "POST,PUT")| the param is required only for the listed request methods
+    param | positive |    no    |    positive   | numerical param's value(or each element) must be > 0
+    param | negative |    no    |    negative   | numerical param's value(or each element) must be < 0
+    param |nonnegative|   no    |  nonnegative  | numerical param's value(or each element) must be >= 0
+    param |  maxbody |    no    |  (e.g. "32")  | the max size(MB) of the `body` param, default 32, exceeding it returns "body too large"
+    param | maxdepth |    no    |  (e.g. "16")  | the max nesting depth of the objects and arrays in the JSON `body` param, scanned before decoding
+    param | presence |    no    |    presence   | bool param is true when present without value, e.g. `?flag`, in both `net/http` and `fasthttp`
+    param |  eqfield |    no    |(e.g. "Password")| param's value must equal the listed struct field's, checked after all params are bound
+    param |  nefield |    no    |(e.g. "OldPassword")| param's value must not equal the listed struct field's, checked after all params are bound
+    param |required_if|   no    |(e.g. "Reason,other")| the param is required when the struct field is formatted as the value
+    param |required_unless| no  |(e.g. "Days,1")| the param is required unless the struct field is formatted as the value
+    param |   isbn   |    no    |      isbn     | param's value must be an ISBN-10 or ISBN-13 with the valid check digit, hyphens are ignored
+    param |   ean13  |    no    |     ean13     | param's value must be an EAN-13 with the valid check digit
+    param |   split  |    no    |   (e.g. ",")  | split each value of the slice param by the separator, e.g. `?p=a,b&p=c`
+    param |  charset |    no    |(e.g. a-zA-Z_)| param's value must only contain the given characters and `a-z` like ranges, only for `string` field
+    param | denychars|    no    |  (e.g. <>&)  | param's value must not contain any of the given characters and `a-z` like ranges, only for `string` field
+    param |    eq    |    no    |  (e.g. true)  | param's value must equal the constant, only for string, number or bool field
+    param |    ne    |    no    |  (e.g. admin) | param's value must not equal the constant, only for string, number or bool field
+    param | readonly |    no    |   readonly    | param is set by the server, binding fails if the client supplies it, otherwise the field is left untouched
+    param |  maxkeys |    no    |   (e.g. 20)   | the captured map param, e.g. `url.Values`, can not have more keys
+    param | maxvalues|    no    |   (e.g. 100)  | the captured map param, e.g. `url.Values`, can not have more values in total
+    param |   trim   |    no    |      trim     | trim the leading and trailing white space of param's value before validating, only for `string` field
+    param |   lower  |    no    |     lower     | lower case param's value before validating, only for `string` field
+    param |   upper  |    no    |     upper     | upper case param's value before validating, only for `string` field
+    param |   bcp47  |    no    |     bcp47     | param's value must be a well-formed BCP 47 language tag, e.g. `en-US`
+    param |  parsers |    no    |(e.g. "trim|base64")| transform each raw value in order before the conversion, the built-in `trim`, `lower`, `upper`, `base64` and `base64url`, and the ones registered by `RegisterTransform`
+    param |  maxsize |    no    |  (e.g. "2mb") | the max size of each uploaded file, in `b`, `kb`, `mb` or `gb`, only for the buffered file field
+    param |  accept  |    no    |(e.g. "image/*|image/png")| the accepted content types of each uploaded file, only for the file field
+    param |maxtotalmb|    no    |   (e.g. "10")  | the max total size(MB) of the uploaded files, only for the file slice field, or the file map field receiving all files
+    param |   uuid   |    no    |      uuid     | param's value(or each element) must be a hyphenated or non-hyphenated UUID, only for `string` field, and a `[16]byte` field like `uuid.UUID` binds the UUID directly
+    param |   email  |    no    |     email     | param's value(or each element) must be a bare email address, parsed by `net/mail`, only for `string` field
+    param |    url   |    no    |      url      | param's value(or each element) must be an absolute URL with the scheme and host, parsed by `url.ParseRequestURI`, only for `string` field
+    param |   ascii  |    no    |     ascii     | param's value(or each element) must be ASCII only, the error points out the first non-ASCII byte, only for `string` field
+    param |  endian  |    no    |  big / little | decode the hex(optional `0x`) or base64 value into the bytes of the fixed-width integer with the byte order, zero-extended, for `int8`~`uint64` field, its pointer or slice
+    param |phone/e164|    no    |  phone / e164 | param's value(or each element) must be an E.164 phone number, a leading `+` and up to 15 digits like `+14155552671`, only for `string` field
     regexp|          |    no    |(e.g. "^\\w+$")| param value can not be null
     err   |          |    no    |(e.g. "incorrect password format")| the custom error for binding or validating
 
     NOTES:
         1. the binding object must be a struct pointer
-        2. the binding struct's field can not be a pointer
+        2. the binding struct's field can not be a pointer, except for the optional base type(e.g. `*int`, `*string`, `*bool`), which is nil when absent
         3. `regexp` or `param` tag is only usable when `param:"type(xxx)"` is exist
         4. if the `param` tag is not exist, anonymous field will be parsed
         5. when the param's position(`in`) is `formData` and the field's type is `multipart.FileHeader`, the param receives file uploaded
         6. if param's position(`in`) is `cookie`, field's type must be `http.Cookie`
         7. param tags `in(formData)` and `in(body)` can not exist at the same time
         8. there should not be more than one `in(body)` param tag
+        9. the quotes of the ETags in the `If-Match` and `If-None-Match` header params are stripped, e.g. `"33a64df5"` is bound as `33a64df5`
 
 List of supported param value types:
     base    |   slice    | special
@@ -50,16 +115,28 @@ List of supported param value types:
     string  |  []string  | [][]byte
     byte    |  []byte    | [][]uint8
     uint8   |  []uint8   | multipart.FileHeader (only for `formData` param)
+            |            | map[string]*multipart.FileHeader (only for `formData` param, receives all uploaded files)
+            |            | []*multipart.FileHeader, []multipart.FileHeader (only for `formData` param, receives all uploaded files of the form field, `required` means at least one file)
+            |            | apiware.FileHandler (only for `formData` param, streams each uploaded file to the handler set before binding, can not be used with the buffered file params)
     bool    |  []bool    | http.Cookie (only for `net/http`'s `cookie` param)
     int     |  []int     | fasthttp.Cookie (only for `fasthttp`'s `cookie` param)
-    int8    |  []int8    | struct (struct type only for `body` param or as an anonymous field to extend params)
-    int16   |  []int16   |
-    int32   |  []int32   |
-    int64   |  []int64   |
-    uint8   |  []uint8   |
-    uint16  |  []uint16  |
-    uint32  |  []uint32  |
-    uint64  |  []uint64  |
+    int8    |  []int8    | struct (struct type only for `body` param or as an untagged anonymous field, also the struct pointer allocated if nil, to extend params)
+            |            | []T, map[K]V (decoded from the top-level JSON array or object of the `body` param)
+    int16   |  []int16   | net.IP
+            |            | time.Time, []time.Time (RFC3339 unless the `time` tag specifies the layout)
+            |            | time.Duration, []time.Duration (parsed by `time.ParseDuration`, e.g. "30s", the bounds of `range`, `min`, `max`, `gt` and `lt` are durations like "1h")
+    int32   |  []int32   | net.HardwareAddr
+    int64   |  []int64   | net.IPNet
+    uint8   |  []uint8   | []apiware.KeyValue (only for `query` param, keeps the order of `name[key]=value`)
+            |            | map[string]string, map[string][]string (only for `query` param, collects `name[key]=value`, or receives all the path params as a `map[string]string` `path` param, the KV must implement `KeysKV`)
+            |            | url.Values (only for `query` param, captures all the query params)
+            |            | io.Reader (only for `body` param, streams the request body, which is not read or closed by apiware, the server closes it after the handler returns)
+            |            | []byte (as a `body` param, receives the raw request body without decoding, e.g. for signature checks)
+    uint16  |  []uint16  | *string, *bool, *int, *float64 and so on (optional base type, nil when absent)
+            |            | *struct (only for `body` param, allocated when the body is present, nil when absent)
+    uint32  |  []uint32  | any type with the converter registered by `RegisterConverter`, `RegisterConverterContext` or `RegisterEnumNames`, which takes precedence
+            |            | []struct (each repeated value is an element, parsed by the converter registered for the struct, `Set(string) error`, or JSON)
+    uint64  |  []uint64  | complex64, complex128, []complex64, []complex128 (the `range`, `min`, `max`, `gt` and `lt` tags check the magnitude)
     float32 |  []float32 |
     float64 |  []float64 |
 */
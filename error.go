@@ -14,6 +14,11 @@
 
 package apiware
 
+import (
+	"encoding/json"
+	"net/http"
+)
+
 const (
 	ValidationErrorValueNotSet = (1<<16 + iota)
 	ValidationErrorValueTooSmall
@@ -21,12 +26,36 @@ const (
 	ValidationErrorValueTooShort
 	ValidationErrorValueTooLong
 	ValidationErrorValueNotMatch
+	ValidationErrorValueNotAllowed
+	ValidationErrorValueEmpty
+	ValidationErrorValueInvalid
+)
+
+// ValidationErrorCode is the machine-readable code of the validation error kind.
+type ValidationErrorCode string
+
+// the codes of the validation error kinds
+const (
+	ValidationErrorCodeNotSet     ValidationErrorCode = "not_set"
+	ValidationErrorCodeTooSmall   ValidationErrorCode = "too_small"
+	ValidationErrorCodeTooBig     ValidationErrorCode = "too_big"
+	ValidationErrorCodeTooShort   ValidationErrorCode = "too_short"
+	ValidationErrorCodeTooLong    ValidationErrorCode = "too_long"
+	ValidationErrorCodeNotMatch   ValidationErrorCode = "not_match"
+	ValidationErrorCodeNotAllowed ValidationErrorCode = "not_allowed"
+	ValidationErrorCodeEmpty      ValidationErrorCode = "empty"
+	ValidationErrorCodeInvalid    ValidationErrorCode = "invalid"
+	ValidationErrorCodeCustom     ValidationErrorCode = "custom" // the custom kind of `NewValidationError`
 )
 
 // Validation error type
 type ValidationError struct {
-	kind  int
-	field string
+	kind   int
+	field  string
+	expect string // the unmet expectation, e.g. `base64`, `>= 1` or `equal password`
+
+	rule       string // the failed rule, i.e. the tag, e.g. `len`
+	constraint string // the value of the failed rule, e.g. `1:10`
 }
 
 // NewValidationError returns a new validation error with the specified id and
@@ -34,7 +63,12 @@ type ValidationError struct {
 // Built-in validation error ids start at 65536, so you should keep your custom
 // ids under that value.
 func NewValidationError(id int, field string) error {
-	return &ValidationError{id, field}
+	return &ValidationError{kind: id, field: field}
+}
+
+// newFormatError returns a validation error for the value which is not in the format, e.g. `base64`.
+func newFormatError(format, field string) error {
+	return &ValidationError{kind: ValidationErrorValueInvalid, field: field, expect: format}
 }
 
 func (e *ValidationError) Error() string {
@@ -44,14 +78,29 @@ func (e *ValidationError) Error() string {
 		kindStr = " not set"
 	case ValidationErrorValueTooBig:
 		kindStr = " too big"
+		if e.expect != "" {
+			kindStr = " must be " + e.expect
+		}
 	case ValidationErrorValueTooLong:
 		kindStr = " too long"
 	case ValidationErrorValueTooSmall:
 		kindStr = " too small"
+		if e.expect != "" {
+			kindStr = " must be " + e.expect
+		}
 	case ValidationErrorValueTooShort:
 		kindStr = " too short"
 	case ValidationErrorValueNotMatch:
 		kindStr = " not match"
+		if e.expect != "" {
+			kindStr = " must " + e.expect
+		}
+	case ValidationErrorValueNotAllowed:
+		kindStr = " not in allowed set"
+	case ValidationErrorValueEmpty:
+		kindStr = " is empty"
+	case ValidationErrorValueInvalid:
+		kindStr = " is not a valid " + e.expect
 	}
 	return e.field + kindStr
 }
@@ -64,18 +113,91 @@ func (e *ValidationError) Field() string {
 	return e.field
 }
 
+// Rule returns the failed tag, e.g. `len`, `range`, `regexp` or `nonzero`,
+// it is empty for the custom validation error.
+func (e *ValidationError) Rule() string {
+	return e.rule
+}
+
+// Constraint returns the value of the failed rule, e.g. `1:10` of `range(1:10)`,
+// it is empty if the tag has no value.
+func (e *ValidationError) Constraint() string {
+	return e.constraint
+}
+
+// validationErrorCodes are the codes of the built-in validation error kinds.
+var validationErrorCodes = map[int]ValidationErrorCode{
+	ValidationErrorValueNotSet:     ValidationErrorCodeNotSet,
+	ValidationErrorValueTooSmall:   ValidationErrorCodeTooSmall,
+	ValidationErrorValueTooBig:     ValidationErrorCodeTooBig,
+	ValidationErrorValueTooShort:   ValidationErrorCodeTooShort,
+	ValidationErrorValueTooLong:    ValidationErrorCodeTooLong,
+	ValidationErrorValueNotMatch:   ValidationErrorCodeNotMatch,
+	ValidationErrorValueNotAllowed: ValidationErrorCodeNotAllowed,
+	ValidationErrorValueEmpty:      ValidationErrorCodeEmpty,
+	ValidationErrorValueInvalid:    ValidationErrorCodeInvalid,
+}
+
+// Code returns the machine-readable code of the error kind, e.g. `too_short`,
+// it is `custom` for the custom kind.
+func (e *ValidationError) Code() ValidationErrorCode {
+	if code, ok := validationErrorCodes[e.kind]; ok {
+		return code
+	}
+	return ValidationErrorCodeCustom
+}
+
+// MarshalJSON encodes the error like `{"field":"p","rule":"len","constraint":"1:10","code":"too_short","message":"p too short"}`.
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Field      string              `json:"field"`
+		Rule       string              `json:"rule,omitempty"`
+		Constraint string              `json:"constraint,omitempty"`
+		Code       ValidationErrorCode `json:"code"`
+		Message    string              `json:"message"`
+	}{e.field, e.rule, e.constraint, e.Code(), e.Error()})
+}
+
+// withTag records the failed tag and its value as the rule and constraint of the validation error.
+func withTag(err error, tag, constraint string) error {
+	if e, ok := err.(*ValidationError); ok {
+		e.rule, e.constraint = tag, constraint
+	}
+	return err
+}
+
 type Error struct {
 	Api    string `json:"api"`
 	Param  string `json:"param"`
 	Reason string `json:"reason"`
+	status int    // the suggested HTTP status code
 }
 
+// NewError returns the error of the api, e.g. the invalid struct tag,
+// its suggested HTTP status code is 500.
 func NewError(api string, param string, reason string) *Error {
 	return &Error{
 		Api:    api,
 		Param:  param,
 		Reason: reason,
+		status: http.StatusInternalServerError,
+	}
+}
+
+// newBindError returns the error of the bad request param, e.g. the missing param,
+// its suggested HTTP status code is 400.
+func newBindError(api string, param string, reason string) *Error {
+	e := NewError(api, param, reason)
+	e.status = http.StatusBadRequest
+	return e
+}
+
+// StatusCode returns the suggested HTTP status code of the error.
+func (e *Error) StatusCode() int {
+	if e.status == 0 {
+		return http.StatusInternalServerError
 	}
+	return e.status
 }
 
 var _ error = new(Error)
@@ -83,3 +205,53 @@ var _ error = new(Error)
 func (e *Error) Error() string {
 	return "[apiware] " + e.Api + " | " + e.Param + " | " + e.Reason
 }
+
+// MultiError is the errors of binding several structs, e.g. by `BindMulti`.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	var s string
+	for i, err := range e {
+		if i > 0 {
+			s += "\n"
+		}
+		s += err.Error()
+	}
+	return s
+}
+
+// StatusCode returns the suggested HTTP status code of the binding error,
+// 400 for the bad request params and the validation errors, 500 for the misconfigured apis,
+// the highest one of a `MultiError`, and 200 for nil.
+// note: the other errors, e.g. the custom error of the `err` tag, are 400.
+func StatusCode(err error) int {
+	switch e := err.(type) {
+	case nil:
+		return http.StatusOK
+	case *Error:
+		return e.StatusCode()
+	case *ValidationError:
+		return http.StatusBadRequest
+	case MultiError:
+		code := http.StatusOK
+		for _, err := range e {
+			if c := StatusCode(err); c > code {
+				code = c
+			}
+		}
+		return code
+	}
+	return http.StatusBadRequest
+}
+
+// toError converts any binding error to `*Error`, so that it can be encoded as JSON,
+// the validation error is localized in the language.
+func toError(api string, err error, lang string) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *ValidationError:
+		return newBindError(api, e.field, e.Message(lang))
+	}
+	return NewError(api, "?", err.Error())
+}
@@ -0,0 +1,61 @@
+//go:build go1.18
+
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiware
+
+import (
+	"net/http"
+	"reflect"
+
+	"github.com/valyala/fasthttp"
+)
+
+// paramsAPIOf returns the ParamsAPI of T, registering it with the default options if needed.
+func paramsAPIOf[T any]() (*ParamsAPI, error) {
+	t := reflect.TypeOf((*T)(nil))
+	paramsAPI, err := GetParamsAPI(t.String())
+	if err != nil {
+		return NewParamsAPI(reflect.New(t.Elem()).Interface(), nil, nil)
+	}
+	if paramsAPI.structType != t.Elem() {
+		return nil, NewError(paramsAPI.name, "*", "the registered type does not match type `"+t.String()+"`")
+	}
+	return paramsAPI, nil
+}
+
+// BindTo binds the net/http request params to a new T and validate it,
+// T is registered with the default options if needed.
+func BindTo[T any](req *http.Request, pathParams KV) (*T, error) {
+	paramsAPI, err := paramsAPIOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	structPointer, fields := paramsAPI.NewReceiver()
+	err = paramsAPI.BindFields(fields, req, pathParams)
+	return structPointer.(*T), err
+}
+
+// FasthttpBindTo binds the fasthttp request params to a new T and validate it,
+// T is registered with the default options if needed.
+func FasthttpBindTo[T any](req *fasthttp.RequestCtx, pathParams KV) (*T, error) {
+	paramsAPI, err := paramsAPIOf[T]()
+	if err != nil {
+		return nil, err
+	}
+	structPointer, fields := paramsAPI.NewReceiver()
+	err = paramsAPI.FasthttpBindFields(fields, req, pathParams)
+	return structPointer.(*T), err
+}
@@ -0,0 +1,46 @@
+//go:build go1.18
+
+package apiware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+type bindToParams struct {
+	ID   int    `param:"in(path),name(id)"`
+	Name string `param:"in(query),nonzero"`
+}
+
+func TestBindTo(t *testing.T) {
+	p, err := BindTo[bindToParams](httptest.NewRequest("GET", "/?name=abc", nil), Map{"id": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 5 || p.Name != "abc" {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	// registered by the first call
+	if _, err = GetParamsAPI("*apiware.bindToParams"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = BindTo[bindToParams](httptest.NewRequest("GET", "/", nil), Map{"id": "5"}); err == nil {
+		t.Fatal("should not validate")
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/?name=xyz")
+	p, err = FasthttpBindTo[bindToParams](ctx, Map{"id": "6"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 6 || p.Name != "xyz" {
+		t.Fatalf("wrong value: %#v", p)
+	}
+
+	if _, err = BindTo[int](httptest.NewRequest("GET", "/", nil), nil); err == nil {
+		t.Fatal("should fail for non-struct type")
+	}
+}
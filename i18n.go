@@ -0,0 +1,122 @@
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiware
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	messages     = map[string]map[ValidationErrorCode]string{}
+	messagesLock sync.RWMutex
+)
+
+// SetMessages registers the localized message templates of the language, e.g. `zh` or `zh-CN`,
+// keyed by the `Code` of ValidationError, e.g. `ValidationErrorCodeTooShort`.
+// The placeholders `{field}`, `{rule}` and `{constraint}` are replaced with the error's,
+// and the language is removed when msgs is nil.
+func SetMessages(lang string, msgs map[ValidationErrorCode]string) {
+	messagesLock.Lock()
+	defer messagesLock.Unlock()
+	lang = strings.ToLower(lang)
+	if msgs == nil {
+		delete(messages, lang)
+		return
+	}
+	m := make(map[ValidationErrorCode]string, len(msgs))
+	for k, v := range msgs {
+		m[k] = v
+	}
+	messages[lang] = m
+}
+
+// matchLang returns the registered language of lang, `zh-CN` falls back to `zh`.
+// note: the caller must hold messagesLock.
+func matchLang(lang string) (string, bool) {
+	lang = strings.ToLower(lang)
+	for {
+		if _, ok := messages[lang]; ok {
+			return lang, true
+		}
+		i := strings.LastIndexByte(lang, '-')
+		if i == -1 {
+			return "", false
+		}
+		lang = lang[:i]
+	}
+}
+
+// lookupMessage returns the template of the code in the language.
+func lookupMessage(lang string, code ValidationErrorCode) (string, bool) {
+	messagesLock.RLock()
+	defer messagesLock.RUnlock()
+	if lang, ok := matchLang(lang); ok {
+		msg, ok := messages[lang][code]
+		return msg, ok
+	}
+	return "", false
+}
+
+// Message returns the message localized by the templates of `SetMessages`,
+// it is the same as `Error()` if the language has no template for the error's code.
+func (e *ValidationError) Message(lang string) string {
+	msg, ok := lookupMessage(lang, e.Code())
+	if !ok {
+		return e.Error()
+	}
+	return strings.NewReplacer(
+		"{field}", e.field,
+		"{rule}", e.rule,
+		"{constraint}", e.constraint,
+	).Replace(msg)
+}
+
+// RequestLang returns the first language of the `Accept-Language` header which has the registered messages,
+// in the order of the quality values, it is empty if none is registered.
+func RequestLang(req *http.Request) string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+	var langs []weighted
+	for _, s := range strings.Split(req.Header.Get("Accept-Language"), ",") {
+		parts := strings.Split(strings.TrimSpace(s), ";")
+		w := weighted{lang: strings.TrimSpace(parts[0]), q: 1}
+		for _, p := range parts[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(p[2:], 64); err == nil {
+					w.q = q
+				}
+			}
+		}
+		if len(w.lang) > 0 && w.lang != "*" && w.q > 0 {
+			langs = append(langs, w)
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	messagesLock.RLock()
+	defer messagesLock.RUnlock()
+	for _, w := range langs {
+		if _, ok := matchLang(w.lang); ok {
+			return w.lang
+		}
+	}
+	return ""
+}
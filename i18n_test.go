@@ -0,0 +1,63 @@
+package apiware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetMessages(t *testing.T) {
+	SetMessages("zh", map[ValidationErrorCode]string{
+		ValidationErrorCodeTooShort: "{field} 太短，长度范围 {constraint}",
+		ValidationErrorCodeNotSet:   "{field} 未设置",
+	})
+	defer SetMessages("zh", nil)
+
+	e := withTag(NewValidationError(ValidationErrorValueTooShort, "p"), "len", "2:8").(*ValidationError)
+	for lang, want := range map[string]string{
+		"zh":    "p 太短，长度范围 2:8",
+		"zh-CN": "p 太短，长度范围 2:8",
+		"en":    "p too short",
+		"":      "p too short",
+	} {
+		if msg := e.Message(lang); msg != want {
+			t.Fatal("wrong message", lang, msg)
+		}
+	}
+	if msg := NewValidationError(ValidationErrorValueTooLong, "p").(*ValidationError).Message("zh"); msg != "p too long" {
+		t.Fatal("should fall back to the default message", msg)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for header, want := range map[string]string{
+		"fr-FR,zh-CN;q=0.8,en;q=0.5": "zh-CN",
+		"en;q=0.9,zh;q=0":            "",
+		"":                           "",
+	} {
+		req.Header.Set("Accept-Language", header)
+		if lang := RequestLang(req); lang != want {
+			t.Fatal("wrong language", header, lang)
+		}
+	}
+
+	type i18nParams struct {
+		Name string `param:"in(query),len(2:8)"`
+	}
+	a := New(testPathDecodeFunc, nil, nil)
+	if err := a.Register(new(i18nParams)); err != nil {
+		t.Fatal(err)
+	}
+	resp := httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/?name=a", nil)
+	req.Header.Set("Accept-Language", "zh-CN")
+	if a.BindAndRespond(resp, req, new(i18nParams), "/") {
+		t.Fatal("should not bind")
+	}
+	var body Error
+	if err := json.Unmarshal(resp.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Reason != "name 太短，长度范围 2:8" {
+		t.Fatal("wrong localized reason", body.Reason)
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package muxadapter feeds the path params parsed by gorilla/mux to apiware,
+// it is a separate package so that apiware does not depend on gorilla/mux.
+//
+//	err := apiware.Bind(params, req, muxadapter.MuxVars(req))
+package muxadapter
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/henrylee2cn/apiware"
+)
+
+// MuxVars returns the path params of the request routed by gorilla/mux,
+// it implements `apiware.KeysKV`, so that the catch-all `map[string]string` path param works too.
+func MuxVars(req *http.Request) apiware.KV {
+	return apiware.Map(mux.Vars(req))
+}
@@ -0,0 +1,40 @@
+package muxadapter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/henrylee2cn/apiware"
+)
+
+type userParams struct {
+	ID   int               `param:"in(path),name(id)"`
+	Name string            `param:"in(path)"`
+	All  map[string]string `param:"in(path)"`
+}
+
+func TestMuxVars(t *testing.T) {
+	if err := apiware.Register(new(userParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var got *userParams
+	r := mux.NewRouter()
+	r.HandleFunc("/users/{id}/{name}", func(w http.ResponseWriter, req *http.Request) {
+		p := new(userParams)
+		if err := apiware.Bind(p, req, MuxVars(req)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = p
+	})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/users/42/gopher", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatal(rec.Code, rec.Body.String())
+	}
+	if got == nil || got.ID != 42 || got.Name != "gopher" || got.All["id"] != "42" || got.All["name"] != "gopher" {
+		t.Fatalf("wrong params %#v", got)
+	}
+}
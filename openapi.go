@@ -0,0 +1,381 @@
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiware
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type (
+	// OpenAPIOperation is the parameters and request body fragment of an OpenAPI 3 operation,
+	// it can be marshaled to JSON directly.
+	OpenAPIOperation struct {
+		Parameters  []*OpenAPIParameter `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody `json:"requestBody,omitempty"`
+	}
+	// OpenAPIParameter is an OpenAPI 3 parameter of `path`, `query`, `header` or `cookie`.
+	OpenAPIParameter struct {
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description,omitempty"`
+		Required    bool           `json:"required,omitempty"`
+		Schema      *OpenAPISchema `json:"schema,omitempty"`
+	}
+	// OpenAPIRequestBody is an OpenAPI 3 request body, keyed by the media types.
+	OpenAPIRequestBody struct {
+		Description string                       `json:"description,omitempty"`
+		Required    bool                         `json:"required,omitempty"`
+		Content     map[string]*OpenAPIMediaType `json:"content"`
+	}
+	// OpenAPIMediaType is the schema of a request body media type.
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema,omitempty"`
+	}
+	// OpenAPISchema is the subset of the OpenAPI 3 schema object that the param tags can express.
+	OpenAPISchema struct {
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		Minimum              *float64                  `json:"minimum,omitempty"`
+		Maximum              *float64                  `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool                      `json:"exclusiveMaximum,omitempty"`
+		MinLength            *int                      `json:"minLength,omitempty"`
+		MaxLength            *int                      `json:"maxLength,omitempty"`
+		Pattern              string                    `json:"pattern,omitempty"`
+		Enum                 []interface{}             `json:"enum,omitempty"`
+		Default              interface{}               `json:"default,omitempty"`
+	}
+)
+
+// OpenAPIOperation generates the OpenAPI 3 parameters and request body of the endpoint,
+// the `path`, `query`, `header` and `cookie` params are the parameters,
+// the `formData` and `body` params are the request body,
+// and the `desc`, `required`, `range`, `min`, `max`, `gt`, `lt`, `len`, `regexp`, `oneof` and `default` tags are carried over.
+// note: the `host`, `scheme`, `auth` and `bodysize` params are not included,
+// and the params of the `required_methods` tag are not required, use `OpenAPIOperationFor` to consider the method.
+func (paramsAPI *ParamsAPI) OpenAPIOperation() (*OpenAPIOperation, error) {
+	return paramsAPI.OpenAPIOperationFor("")
+}
+
+// OpenAPIOperationFor is like OpenAPIOperation, but the params of the `required_methods` tag
+// are required if the operation method is one of them.
+func (paramsAPI *ParamsAPI) OpenAPIOperationFor(method string) (*OpenAPIOperation, error) {
+	op := new(OpenAPIOperation)
+	var form *OpenAPISchema
+	var hasFile bool
+	for _, param := range paramsAPI.params {
+		schema := openAPISchemaOf(param.rawValue.Type(), nil)
+		if err := param.constrainSchema(schema); err != nil {
+			return nil, NewError(paramsAPI.name, param.name, "invalid tag for OpenAPI: "+err.Error())
+		}
+		switch param.In() {
+		case "path", "query", "header", "cookie":
+			op.Parameters = append(op.Parameters, &OpenAPIParameter{
+				Name:        param.name,
+				In:          param.In(),
+				Description: param.Description(),
+				Required:    param.requiredFor(method),
+				Schema:      schema,
+			})
+		case "formData":
+			if form == nil {
+				form = &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+			}
+			schema.Description = param.Description()
+			form.Properties[param.name] = schema
+			if param.requiredFor(method) {
+				form.Required = append(form.Required, param.name)
+			}
+			hasFile = hasFile || param.isFile
+		case "body":
+			body := &OpenAPIRequestBody{
+				Description: param.Description(),
+				Required:    param.requiredFor(method),
+				Content:     make(map[string]*OpenAPIMediaType),
+			}
+			if len(paramsAPI.bodyDecoderMux) > 0 {
+				for mediaType := range paramsAPI.bodyDecoderMux {
+					body.Content[mediaType] = &OpenAPIMediaType{Schema: schema}
+				}
+			} else {
+				body.Content["application/json"] = &OpenAPIMediaType{Schema: schema}
+			}
+			op.RequestBody = body
+		}
+	}
+	if form != nil {
+		mediaType := "application/x-www-form-urlencoded"
+		if hasFile {
+			mediaType = "multipart/form-data"
+		}
+		op.RequestBody = &OpenAPIRequestBody{
+			Required: len(form.Required) > 0,
+			Content:  map[string]*OpenAPIMediaType{mediaType: {Schema: form}},
+		}
+	}
+	return op, nil
+}
+
+// constrainSchema carries the validation tags over to the schema, or to its items for a slice param.
+func (param *Param) constrainSchema(schema *OpenAPISchema) error {
+	if param.defaults != nil {
+		var err error
+		if schema.Default, err = openAPIDefault(schema, param.defaults); err != nil {
+			return err
+		}
+	}
+	if schema.Type == "array" && schema.Items != nil {
+		schema = schema.Items
+	}
+	// the duration is a string like `1h30m` in the schema, its bounds are not numeric
+	if !param.isDuration {
+		if tuple, ok := param.tags["range"]; ok {
+			a, b, err := splitTuple(tuple)
+			if err != nil {
+				return err
+			}
+			if len(a) > 0 {
+				f, err := parseBound(a)
+				if err != nil {
+					return err
+				}
+				schema.tightenMinimum(f, false)
+			}
+			if len(b) > 0 {
+				f, err := parseBound(b)
+				if err != nil {
+					return err
+				}
+				schema.tightenMaximum(f, false)
+			}
+		}
+		// several bounds on the same side keep the tightest one, whatever the map order is
+		for k, op := range numberBoundOps {
+			bound, ok := param.tags[k]
+			if !ok {
+				continue
+			}
+			f, err := parseBound(bound)
+			if err != nil {
+				return err
+			}
+			switch op {
+			case ">=", ">":
+				schema.tightenMinimum(f, op == ">")
+			default:
+				schema.tightenMaximum(f, op == "<")
+			}
+		}
+		for k, bound := range signBounds {
+			if _, ok := param.tags[k]; ok {
+				f, _ := parseBound(bound[1])
+				if bound[0] == "lt" {
+					schema.tightenMaximum(f, true)
+				} else {
+					schema.tightenMinimum(f, bound[0] == "gt")
+				}
+			}
+		}
+	}
+	if tuple, ok := param.tags["len"]; ok {
+		a, b, err := splitTuple(tuple)
+		if err != nil {
+			return err
+		}
+		if len(a) > 0 {
+			if schema.MinLength, err = parseLength(a); err != nil {
+				return err
+			}
+		}
+		if len(b) > 0 {
+			if schema.MaxLength, err = parseLength(b); err != nil {
+				return err
+			}
+		}
+	}
+	schema.Pattern = param.tags[TAG_REGEXP]
+	if _, ok := param.tags["uuid"]; ok {
+		schema.Format = "uuid"
+	}
+	if list, ok := param.tags["oneof"]; ok {
+		for _, v := range strings.Split(list, "|") {
+			if schema.Type == "integer" {
+				i, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					return err
+				}
+				schema.Enum = append(schema.Enum, i)
+			} else {
+				schema.Enum = append(schema.Enum, v)
+			}
+		}
+	}
+	return nil
+}
+
+// openAPIDefault converts the values of the `default` tag to the type of the schema,
+// it is an array of them for the slice param.
+func openAPIDefault(schema *OpenAPISchema, defaults []string) (interface{}, error) {
+	if schema.Type != "array" || schema.Items == nil {
+		return openAPIValue(schema.Type, defaults[0])
+	}
+	list := make([]interface{}, len(defaults))
+	for i, v := range defaults {
+		var err error
+		if list[i], err = openAPIValue(schema.Items.Type, v); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+// openAPIValue converts the string to the value of the schema type.
+func openAPIValue(typ, s string) (interface{}, error) {
+	switch typ {
+	case "integer":
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case "number":
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case "boolean":
+		return parseBool(s), nil
+	}
+	return s, nil
+}
+
+// splitTuple is similar to a `parseTuple`, but returns an error instead of panicking.
+func splitTuple(tuple string) (a, b string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = errors.New("invalid tuple `" + tuple + "`")
+		}
+	}()
+	a, b = parseTuple(tuple)
+	return
+}
+
+// tightenMinimum sets the lower bound unless the current one is tighter,
+// the exclusive bound is tighter than the inclusive one of the same value.
+func (schema *OpenAPISchema) tightenMinimum(f *float64, exclusive bool) {
+	if schema.Minimum == nil || *f > *schema.Minimum || *f == *schema.Minimum && exclusive {
+		schema.Minimum, schema.ExclusiveMinimum = f, exclusive
+	}
+}
+
+// tightenMaximum sets the upper bound unless the current one is tighter,
+// the exclusive bound is tighter than the inclusive one of the same value.
+func (schema *OpenAPISchema) tightenMaximum(f *float64, exclusive bool) {
+	if schema.Maximum == nil || *f < *schema.Maximum || *f == *schema.Maximum && exclusive {
+		schema.Maximum, schema.ExclusiveMaximum = f, exclusive
+	}
+}
+
+func parseBound(s string) (*float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func parseLength(s string) (*int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// openAPISchemaOf returns the schema of the Go type, the struct fields are named by their `json` tags,
+// and the recursive struct types are plain objects.
+func openAPISchemaOf(t reflect.Type, visiting map[reflect.Type]bool) *OpenAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &OpenAPISchema{Type: "string", Format: "duration"}
+	case bytesType:
+		return &OpenAPISchema{Type: "string", Format: "byte"}
+	case readerType:
+		return &OpenAPISchema{Type: "string", Format: "binary"}
+	}
+	if t.String() == fileTypeString || t == fileHandlerType {
+		return &OpenAPISchema{Type: "string", Format: "binary"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &OpenAPISchema{Type: "integer"}
+	case reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &OpenAPISchema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Array:
+		if t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
+			return &OpenAPISchema{Type: "string", Format: "uuid"}
+		}
+		return &OpenAPISchema{Type: "array", Items: openAPISchemaOf(t.Elem(), visiting)}
+	case reflect.Slice:
+		return &OpenAPISchema{Type: "array", Items: openAPISchemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: openAPISchemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		schema := &OpenAPISchema{Type: "object"}
+		if visiting[t] {
+			return schema
+		}
+		if visiting == nil {
+			visiting = make(map[reflect.Type]bool)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema.Properties = make(map[string]*OpenAPISchema)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			name := field.Name
+			if tag, ok := field.Tag.Lookup("json"); ok {
+				if tag == "-" {
+					continue
+				}
+				if n := strings.Split(tag, ",")[0]; len(n) > 0 {
+					name = n
+				}
+			}
+			schema.Properties[name] = openAPISchemaOf(field.Type, visiting)
+		}
+		return schema
+	}
+	return &OpenAPISchema{}
+}
@@ -0,0 +1,162 @@
+package apiware
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIOperation(t *testing.T) {
+	type openAPIBody struct {
+		Title string   `json:"title"`
+		Tags  []string `json:"tags,omitempty"`
+		Skip  string   `json:"-"`
+	}
+	type openAPIParams struct {
+		ID    int         `param:"in(path),name(id),desc(the user id),range(1:100)"`
+		Name  string      `param:"in(query),len(2:8)" regexp:"^[a-z]+$"`
+		Sort  []string    `param:"in(query),oneof(asc|desc)"`
+		Page  int         `param:"in(query),positive,default(1)"`
+		Token string      `param:"in(header),name(X-Token),required"`
+		Host  string      `param:"in(host)"`
+		Body  openAPIBody `param:"in(body),required"`
+	}
+	m, err := NewParamsAPI(new(openAPIParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := m.OpenAPIOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(op.Parameters) != 5 {
+		t.Fatal("wrong number of parameters", len(op.Parameters))
+	}
+	id := op.Parameters[0]
+	if id.In != "path" || !id.Required || id.Description != "the user id" || id.Schema.Type != "integer" ||
+		*id.Schema.Minimum != 1 || *id.Schema.Maximum != 100 {
+		t.Fatalf("wrong id parameter: %#v", id.Schema)
+	}
+	name := op.Parameters[1].Schema
+	if *name.MinLength != 2 || *name.MaxLength != 8 || name.Pattern != "^[a-z]+$" {
+		t.Fatalf("wrong name schema: %#v", name)
+	}
+	if sort := op.Parameters[2].Schema; sort.Type != "array" || !reflect.DeepEqual(sort.Items.Enum, []interface{}{"asc", "desc"}) {
+		t.Fatalf("wrong sort schema: %#v", sort)
+	}
+	if page := op.Parameters[3].Schema; *page.Minimum != 0 || !page.ExclusiveMinimum || page.Default != int64(1) {
+		t.Fatalf("wrong page schema: %#v", page)
+	}
+	if token := op.Parameters[4]; token.In != "header" || token.Name != "X-Token" || !token.Required {
+		t.Fatalf("wrong token parameter: %#v", token)
+	}
+	body := op.RequestBody
+	if body == nil || !body.Required {
+		t.Fatal("wrong request body", body)
+	}
+	schema := body.Content["application/json"].Schema
+	if schema.Type != "object" || len(schema.Properties) != 2 || schema.Properties["tags"].Items.Type != "string" {
+		t.Fatalf("wrong body schema: %#v", schema)
+	}
+	if _, err = json.Marshal(op); err != nil {
+		t.Fatal(err)
+	}
+
+	type openAPIFormParams struct {
+		Avatar multipart.FileHeader `param:"in(formData),required"`
+		Note   string               `param:"in(formData)"`
+	}
+	m, err = NewParamsAPI(new(openAPIFormParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op, err = m.OpenAPIOperation(); err != nil {
+		t.Fatal(err)
+	}
+	form := op.RequestBody.Content["multipart/form-data"].Schema
+	if form.Properties["avatar"].Format != "binary" || !reflect.DeepEqual(form.Required, []string{"avatar"}) {
+		t.Fatalf("wrong form schema: %#v", form)
+	}
+}
+
+func TestOpenAPIBoundsOrder(t *testing.T) {
+	type boundsParams struct {
+		Low  int `param:"in(query),min(5),gt(3),range(0:100)"`
+		High int `param:"in(query),max(10),lt(10)"`
+		Sign int `param:"in(query),nonnegative,positive,lt(50),max(20)"`
+	}
+	m, err := NewParamsAPI(new(boundsParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the map order of the bound tags must not change the schema
+	for i := 0; i < 20; i++ {
+		op, err := m.OpenAPIOperation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		low, high, sign := op.Parameters[0].Schema, op.Parameters[1].Schema, op.Parameters[2].Schema
+		if *low.Minimum != 5 || low.ExclusiveMinimum || *low.Maximum != 100 || low.ExclusiveMaximum {
+			t.Fatalf("wrong low schema: %v %v %v %v", *low.Minimum, low.ExclusiveMinimum, *low.Maximum, low.ExclusiveMaximum)
+		}
+		if high.Minimum != nil || *high.Maximum != 10 || !high.ExclusiveMaximum {
+			t.Fatalf("wrong high schema: %v %v", *high.Maximum, high.ExclusiveMaximum)
+		}
+		if *sign.Minimum != 0 || !sign.ExclusiveMinimum || *sign.Maximum != 20 || sign.ExclusiveMaximum {
+			t.Fatalf("wrong sign schema: %v %v %v %v", *sign.Minimum, sign.ExclusiveMinimum, *sign.Maximum, sign.ExclusiveMaximum)
+		}
+	}
+}
+
+func TestOpenAPIDefaultAndMethods(t *testing.T) {
+	type defaultParams struct {
+		Page  int      `param:"in(query),default(2)"`
+		Ratio float64  `param:"in(query),default(0.5)"`
+		Deep  bool     `param:"in(query),default(true)"`
+		Ids   []int    `param:"in(query),default(1,2)"`
+		Sort  string   `param:"in(query),default(id)"`
+		Token string   `param:"in(header),name(X-Token),required_methods(POST,PUT)"`
+		Tags  []string `param:"in(formData),required_methods(POST)"`
+	}
+	m, err := NewParamsAPI(new(defaultParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := m.OpenAPIOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var defaults []interface{}
+	for _, p := range op.Parameters[:5] {
+		defaults = append(defaults, p.Schema.Default)
+	}
+	want := []interface{}{int64(2), 0.5, true, []interface{}{int64(1), int64(2)}, "id"}
+	if !reflect.DeepEqual(defaults, want) {
+		t.Fatalf("wrong defaults: %#v", defaults)
+	}
+	b, err := json.Marshal(op.Parameters[3].Schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"default":[1,2]`) {
+		t.Fatal("wrong json default", string(b))
+	}
+	// the method is unknown
+	if op.Parameters[5].Required || op.RequestBody.Required {
+		t.Fatal("should not require the params of the required_methods tag")
+	}
+	if op, err = m.OpenAPIOperationFor("post"); err != nil {
+		t.Fatal(err)
+	}
+	if !op.Parameters[5].Required || !op.RequestBody.Required {
+		t.Fatal("should require the params for POST")
+	}
+	if op, err = m.OpenAPIOperationFor("PUT"); err != nil {
+		t.Fatal(err)
+	}
+	if !op.Parameters[5].Required || op.RequestBody.Required {
+		t.Fatal("should require the token only for PUT")
+	}
+}
@@ -15,12 +15,26 @@
 package apiware
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"math/cmplx"
+	"mime/multipart"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -28,10 +42,12 @@ const (
 	TAG_REGEXP       = "regexp" //regexp validate tag name(optio)
 	TAG_ERR          = "err"    //the custom error for binding or validating
 	TAG_IGNORE_PARAM = "-"      //ignore request param tag value
+	TAG_SKIP_PARAM   = "skip"   //ignore request param tag value, distinct from the dash-named param `name(-)`
 
 	MB                 = 1 << 20 // 1MB
 	defaultMaxMemory   = 32 * MB // 32 MB
 	defaultMaxMemoryMB = 32
+	defaultMaxBody     = 32 * MB // 32 MB
 )
 
 // func ParseTags(s string) map[string]string {
@@ -48,8 +64,10 @@ const (
 // 	return m
 // }
 
+// ParseTags parses the `param` tag value, the commas inside parentheses do not split it,
+// e.g. `in(query),required_with(A,B)`.
 func ParseTags(s string) map[string]string {
-	c := strings.Split(s, ",")
+	c := splitTags(s)
 	m := make(map[string]string)
 	for _, v := range c {
 		a := strings.IndexByte(v, '(')
@@ -63,23 +81,129 @@ func ParseTags(s string) map[string]string {
 	return m
 }
 
+// splitTags splits s by the commas which are not inside parentheses.
+func splitTags(s string) []string {
+	var c []string
+	var depth, start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				c = append(c, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(c, s[start:])
+}
+
 // use the struct field to define a request parameter model
 type Param struct {
-	apiName    string // ParamsAPI name
-	name       string // param name
-	indexPath  []int
-	isRequired bool              // file is required or not
-	isFile     bool              // is file param or not
-	tags       map[string]string // struct tags for this param
-	rawTag     reflect.StructTag // the raw tag
-	rawValue   reflect.Value     // the raw tag value
-	err        error             // the custom error for binding or validating
+	apiName         string // ParamsAPI name
+	name            string // param name
+	fieldName       string // struct field name
+	indexPath       []int
+	node            int                            // the cached embedded struct of the field, -1 for the root struct
+	isRequired      bool                           // file is required or not
+	isFile          bool                           // is file param or not
+	noValidate      bool                           // bind the param without validating
+	readonly        bool                           // the param is set by the server, and can not be supplied by the client
+	lenientBool     bool                           // the invalid bool value falls back to the default, by the `LenientBool` option
+	tags            map[string]string              // struct tags for this param
+	rawTag          reflect.StructTag              // the raw tag
+	rawValue        reflect.Value                  // the raw tag value
+	err             error                          // the custom error for binding or validating
+	sources         []paramSource                  // the ordered sources of the `fallback` tag
+	defaults        []string                       // the values of the `default` tag
+	requiredMethods []string                       // the upper case methods of the `required_methods` tag
+	allowChars      *charSet                       // the parsed `charset` tag
+	denyChars       *charSet                       // the parsed `denychars` tag
+	eq, ne          reflect.Value                  // the parsed `eq` and `ne` tags
+	maxDepth        int                            // the parsed `maxdepth` tag of the JSON body
+	parsers         []func(string) (string, error) // the transform chain of the `parsers` tag
+	normalizers     []func(string) (string, error) // the transform chain of the `trim`, `lower` and `upper` tags
+	maxSize         int64                          // the parsed `maxsize` tag of the file param
+	maxTotalSize    int64                          // the parsed `maxtotalmb` tag of the files param
+	regexp          *regexp.Regexp                 // the TAG_REGEXP compiled when registering
+	after, before   time.Time                      // the parsed `after` and `before` tags, in the layout of the `time` tag
+	isDuration      bool                           // the numeric bounds are durations, e.g. `max(1h)`, for `time.Duration` field
+	byteOrder       binary.ByteOrder               // the parsed `endian` tag
+	assigner        atomic.Value                   // the *preparedAssign of the field type, see `prepare`
+}
+
+// preparedAssign is the assigner resolved for the param's field type, with the version of the converters.
+type preparedAssign struct {
+	t       reflect.Type
+	fn      assignFunc
+	version uint64
+}
+
+// paramSource is a position and name to look up a request param
+type paramSource struct {
+	in   string
+	name string
+}
+
+// parseSources parses the `fallback` tag value, e.g. `header:X-Token,query:token`,
+// the name can be omitted to use the param name.
+func parseSources(list string) ([]paramSource, error) {
+	var sources []paramSource
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		source := paramSource{in: s}
+		if i := strings.IndexByte(s, ':'); i != -1 {
+			source.in, source.name = s[:i], s[i+1:]
+		}
+		switch source.in {
+		case "path", "query", "formData", "header", "cookie":
+		default:
+			return nil, fmt.Errorf("invalid source `%s`, refer to the following: `path`, `query`, `formData`, `header` or `cookie`", s)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// supplied tests if the client supplies the param, at its position or any of its `fallback` sources.
+func (param *Param) supplied(lookup func(paramSource) ([]string, bool)) bool {
+	if len(param.sources) > 0 {
+		_, ok := param.lookupSources(lookup)
+		return ok
+	}
+	_, ok := lookup(paramSource{in: param.In(), name: param.name})
+	return ok
+}
+
+// lookupSources returns the values of the first source which supplies the param.
+func (param *Param) lookupSources(lookup func(paramSource) ([]string, bool)) ([]string, bool) {
+	for _, source := range param.sources {
+		if values, ok := lookup(source); ok {
+			return values, true
+		}
+	}
+	return nil, false
 }
 
 const (
 	fileTypeString           = "multipart.FileHeader"
+	fileMapTypeString        = "map[string]*multipart.FileHeader"
+	filesTypeString          = "[]*multipart.FileHeader"
+	files2TypeString         = "[]multipart.FileHeader"
 	cookieTypeString         = "http.Cookie"
 	fasthttpCookieTypeString = "fasthttp.Cookie"
+	keyValuesTypeString      = "[]apiware.KeyValue"
+	stringMapTypeString      = "map[string]string"
+	stringsMapTypeString     = "map[string][]string"
+	readerTypeString         = "io.Reader"
+	urlValuesTypeString      = "url.Values"
+	authTypeString           = "apiware.Authorization"
+	fileHandlerTypeString    = "apiware.FileHandler"
 	stringTypeString         = "string"
 	bytesTypeString          = "[]byte"
 	bytes2TypeString         = "[]uint8"
@@ -94,6 +218,82 @@ var (
 		"body":     true,
 		"header":   true,
 		"cookie":   true,
+		"host":     true,
+		"scheme":   true,
+		"auth":     true,
+		"bodysize": true,
+	}
+
+	// keys of tag 'param', used by the `StrictTags` registration
+	paramTagKeys = map[string]bool{
+		"in":               true,
+		"name":             true,
+		"required":         true,
+		"desc":             true,
+		"len":              true,
+		"range":            true,
+		"nonzero":          true,
+		"maxmb":            true,
+		"oneof":            true,
+		"ci":               true,
+		"canon":            true,
+		"required_with":    true,
+		"sortfields":       true,
+		"fallback":         true,
+		"nonempty":         true,
+		"base64":           true,
+		"base64url":        true,
+		"latitude":         true,
+		"longitude":        true,
+		"novalidate":       true,
+		"time":             true,
+		"sanitize":         true,
+		"after":            true,
+		"before":           true,
+		"min":              true,
+		"max":              true,
+		"gt":               true,
+		"lt":               true,
+		"default":          true,
+		"hostname":         true,
+		"fqdn":             true,
+		"semver":           true,
+		"required_methods": true,
+		"positive":         true,
+		"negative":         true,
+		"nonnegative":      true,
+		"maxbody":          true,
+		"maxdepth":         true,
+		"parsers":          true,
+		"maxsize":          true,
+		"accept":           true,
+		"maxtotalmb":       true,
+		"uuid":             true,
+		"email":            true,
+		"url":              true,
+		"ascii":            true,
+		"phone":            true,
+		"e164":             true,
+		"endian":           true,
+		"presence":         true,
+		"eqfield":          true,
+		"nefield":          true,
+		"required_if":      true,
+		"required_unless":  true,
+		"isbn":             true,
+		"ean13":            true,
+		"split":            true,
+		"charset":          true,
+		"denychars":        true,
+		"eq":               true,
+		"ne":               true,
+		"readonly":         true,
+		"maxkeys":          true,
+		"maxvalues":        true,
+		"trim":             true,
+		"lower":            true,
+		"upper":            true,
+		"bcp47":            true,
 	}
 )
 
@@ -122,29 +322,369 @@ func (param *Param) IsRequired() bool {
 	return param.isRequired
 }
 
+// requiredFor tests if the param is required for the request method,
+// considering the `required_methods` tag.
+func (param *Param) requiredFor(method string) bool {
+	if param.requiredMethods == nil {
+		return param.isRequired
+	}
+	for _, m := range param.requiredMethods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
 // Description gets the description value for the param
 func (param *Param) Description() string {
 	return param.tags["desc"]
 }
 
-// IsFile tests if the param is type *multipart.FileHeader
+// IsFile tests if the param is type multipart.FileHeader or map[string]*multipart.FileHeader
 func (param *Param) IsFile() bool {
 	return param.isFile
 }
 
+// ParamInfo is a read-only view of the param metadata, e.g. for generating the API documents.
+type ParamInfo struct {
+	Name        string            // param name
+	In          string            // position of param, e.g. `query`
+	Type        string            // Go type of the struct field, e.g. `[]int`
+	FieldName   string            // struct field name
+	Required    bool              // the param is required or not
+	Description string            // the `desc` tag
+	Regexp      string            // the `regexp` tag
+	Len         string            // the `len` tag, e.g. `3:6`
+	Range       string            // the `range` tag, e.g. `0:10`
+	IsFile      bool              // the param is a file or not
+	Tags        map[string]string // a copy of all the `param` tags
+}
+
+// Info returns the param metadata.
+func (param *Param) Info() ParamInfo {
+	tags := make(map[string]string, len(param.tags))
+	for k, v := range param.tags {
+		tags[k] = v
+	}
+	return ParamInfo{
+		Name:        param.name,
+		In:          param.In(),
+		Type:        param.rawValue.Type().String(),
+		FieldName:   param.fieldName,
+		Required:    param.isRequired,
+		Description: param.Description(),
+		Regexp:      param.tags[TAG_REGEXP],
+		Len:         param.tags["len"],
+		Range:       param.tags["range"],
+		IsFile:      param.isFile,
+		Tags:        tags,
+	}
+}
+
+var (
+	sanitizer     func(string) string
+	sanitizerLock sync.RWMutex
+)
+
+// SetSanitizer sets the function to sanitize the string params with the `sanitize` tag after binding,
+// e.g. stripping HTML tags against XSS. The default is no-op.
+func SetSanitizer(fn func(string) string) {
+	sanitizerLock.Lock()
+	defer sanitizerLock.Unlock()
+	sanitizer = fn
+}
+
+// sanitize runs the sanitizer on the string or []string value.
+func sanitize(value reflect.Value) {
+	sanitizerLock.RLock()
+	fn := sanitizer
+	sanitizerLock.RUnlock()
+	if fn == nil {
+		return
+	}
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(fn(value.String()))
+	case reflect.Slice:
+		for i, count := 0, value.Len(); i < count; i++ {
+			elem := value.Index(i)
+			elem.SetString(fn(elem.String()))
+		}
+	}
+}
+
+// normalize applies the `trim`, `lower` and `upper` tags to each value, before converting and validating,
+// by the transforms of the same names.
+func (param *Param) normalize(src []string) ([]string, error) {
+	if len(param.normalizers) == 0 {
+		return src, nil
+	}
+	return applyTransforms(param.normalizers, src)
+}
+
+// assign converts the request values and assigns them to the param's field.
+func (param *Param) assign(ctx context.Context, value reflect.Value, src []string) error {
+	if sep, ok := param.tags["split"]; ok {
+		// each occurrence is split, e.g. `?p=a,b&p=c` is `[]string{"a", "b", "c"}`
+		var values []string
+		for _, s := range src {
+			values = append(values, strings.Split(s, sep)...)
+		}
+		src = values
+	}
+	var err error
+	if len(param.parsers) > 0 {
+		if src, err = applyTransforms(param.parsers, src); err != nil {
+			return err
+		}
+	}
+	if src, err = param.normalize(src); err != nil {
+		return err
+	}
+	if _, ok := param.tags["sanitize"]; ok {
+		if err := convertAssignWith(ctx, value, src, param.resolveAssign); err != nil {
+			return err
+		}
+		sanitize(value)
+		return nil
+	}
+	if _, ok := param.tags["presence"]; ok && len(src) > 0 && len(src[0]) == 0 {
+		// the no-value form `?flag`
+		src = []string{"true"}
+	}
+	if param.lenientBool && len(src) > 0 && !isBoolWord(src[0]) {
+		if param.defaults == nil {
+			return nil
+		}
+		src = param.defaults
+	}
+	if list, ok := param.tags["sortfields"]; ok {
+		return convertSortFields(value, src, strings.Split(list, ","))
+	}
+	if layout, ok := param.tags["time"]; ok {
+		return convertTime(value, src, layout)
+	}
+	if param.byteOrder != nil {
+		return convertEndian(value, src, param.byteOrder)
+	}
+	return convertAssignWith(ctx, value, src, param.resolveAssign)
+}
+
+// prepare resolves the assigner of the field type, e.g. the registered converter or `Set(string) error`,
+// so that it is not looked up again by the bindings until the converters change.
+func (param *Param) prepare() *preparedAssign {
+	version := atomic.LoadUint64(&convertersVersion)
+	t := param.rawValue.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	p := &preparedAssign{t: t, fn: resolveAssign(t), version: version}
+	param.assigner.Store(p)
+	return p
+}
+
+// resolveAssign returns the prepared assigner of the field type, it is prepared by the first binding
+// unless `WarmUp` is called, and again after the converters change.
+func (param *Param) resolveAssign(t reflect.Type) assignFunc {
+	p, _ := param.assigner.Load().(*preparedAssign)
+	if p == nil || p.version != atomic.LoadUint64(&convertersVersion) {
+		p = param.prepare()
+	}
+	if p.t != t {
+		// e.g. the element of the indexed slice param
+		return resolveAssign(t)
+	}
+	return p.fn
+}
+
+// assignDefault assigns the values of the `default` tag when the param is absent.
+func (param *Param) assignDefault(ctx context.Context, value reflect.Value) error {
+	if param.defaults == nil {
+		return nil
+	}
+	return param.assign(ctx, value, param.defaults)
+}
+
+// noValueDefaults reports whether the no-value query param, e.g. `?name`, falls back to the `default` tag,
+// unless the `presence` tag is set, so that it is bound in the same way by `net/http` and `fasthttp`.
+// note: the empty value, e.g. `?name=`, is still assigned.
+func (param *Param) noValueDefaults(rawQuery string) bool {
+	if param.defaults == nil {
+		return false
+	}
+	if _, ok := param.tags["presence"]; ok {
+		return false
+	}
+	return isNoValueArg(rawQuery, param.name)
+}
+
+// validateFile tests if the uploaded file conforms to the file constraints.
+func (param *Param) validateFile(fh *multipart.FileHeader) error {
+	if param.noValidate {
+		return nil
+	}
+	if _, ok := param.tags["nonempty"]; ok && fh.Size == 0 {
+		return withTag(param.myValidationError(ValidationErrorValueEmpty), "nonempty", "")
+	}
+	if size, ok := param.tags["maxsize"]; ok && fh.Size > param.maxSize {
+		return withTag(param.myKindError(ValidationErrorValueTooBig, "at most "+size), "maxsize", size)
+	}
+	if list, ok := param.tags["accept"]; ok && !acceptMediaType(fh.Header.Get("Content-Type"), list) {
+		return withTag(param.myFieldError("have the content type `"+list+"`"), "accept", list)
+	}
+	return nil
+}
+
+// isFileTypeString tests if the field type receives the uploaded files.
+func isFileTypeString(s string) bool {
+	switch s {
+	case fileTypeString, fileMapTypeString, filesTypeString, files2TypeString, fileHandlerTypeString:
+		return true
+	}
+	return false
+}
+
+// assignFiles assigns all the uploaded files of the form field to the slice field.
+func (param *Param) assignFiles(value reflect.Value, fhs []*multipart.FileHeader) error {
+	files := reflect.MakeSlice(value.Type(), len(fhs), len(fhs))
+	var total int64
+	for i, fh := range fhs {
+		if err := param.validateFile(fh); err != nil {
+			return err
+		}
+		if total += fh.Size; param.maxTotalSize > 0 && total > param.maxTotalSize {
+			return param.totalSizeError()
+		}
+		if value.Type().Elem().Kind() == reflect.Ptr {
+			files.Index(i).Set(reflect.ValueOf(fh))
+		} else {
+			files.Index(i).Set(reflect.ValueOf(fh).Elem())
+		}
+	}
+	value.Set(files)
+	return nil
+}
+
+// totalSizeError returns the error of the files exceeding the `maxtotalmb` tag.
+func (param *Param) totalSizeError() error {
+	mb := param.tags["maxtotalmb"]
+	return withTag(param.myKindError(ValidationErrorValueTooBig, "at most "+mb+"MB in total"), "maxtotalmb", mb)
+}
+
+// handleFile invokes the `FileHandler` param with the file.
+func (param *Param) handleFile(value reflect.Value, fh *multipart.FileHeader, r io.Reader) error {
+	fn, _ := value.Interface().(FileHandler)
+	if fn == nil {
+		return param.myError("the file handler is not set")
+	}
+	if err := param.validateFile(fh); err != nil {
+		return err
+	}
+	if err := fn(fh, r); err != nil {
+		return param.myError(err.Error())
+	}
+	return nil
+}
+
+// handleFiles invokes the `FileHandler` param with each of the parsed files,
+// and reports whether any file is handled.
+func (param *Param) handleFiles(value reflect.Value, fhs []*multipart.FileHeader) (bool, error) {
+	for _, fh := range fhs {
+		f, err := fh.Open()
+		if err != nil {
+			return true, param.myError(err.Error())
+		}
+		err = param.handleFile(value, fh, f)
+		f.Close()
+		if err != nil {
+			return true, err
+		}
+	}
+	return len(fhs) > 0, nil
+}
+
+// assignFileMap assigns the first uploaded file of each form field to the map field,
+// and reports whether any file is uploaded.
+func (param *Param) assignFileMap(value reflect.Value, form *multipart.Form) (bool, error) {
+	if form == nil || len(form.File) == 0 {
+		return false, nil
+	}
+	files := make(map[string]*multipart.FileHeader, len(form.File))
+	var total int64
+	for name, fhs := range form.File {
+		if len(fhs) == 0 {
+			continue
+		}
+		if err := param.validateFile(fhs[0]); err != nil {
+			return true, err
+		}
+		if total += fhs[0].Size; param.maxTotalSize > 0 && total > param.maxTotalSize {
+			return true, param.totalSizeError()
+		}
+		files[name] = fhs[0]
+	}
+	value.Set(reflect.ValueOf(files))
+	return len(files) > 0, nil
+}
+
 func (param *Param) validate(value reflect.Value) error {
+	if param.noValidate {
+		return nil
+	}
+	if value.Kind() == reflect.Ptr {
+		// the absent optional base type
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Map {
+		if err := param.validateMap(value); err != nil {
+			return err
+		}
+	}
 	if value.Kind() != reflect.Slice {
 		return param.validateElem(value)
 	}
 	var err error
 	for i, count := 0, value.Len(); i < count; i++ {
 		if err = param.validateElem(value.Index(i)); err != nil {
+			// point out the failed element
+			if e, ok := err.(*ValidationError); ok {
+				e.field = fmt.Sprintf("%s[%d]", e.field, i)
+			}
 			return err
 		}
 	}
 	return nil
 }
 
+// validateMap tests the number of the keys and values of the captured map param,
+// against the `maxkeys` and `maxvalues` tags.
+func (param *Param) validateMap(value reflect.Value) error {
+	if a, ok := param.tags["maxkeys"]; ok {
+		if max, _ := strconv.Atoi(a); value.Len() > max {
+			return withTag(param.myFieldError("have at most "+a+" keys"), "maxkeys", a)
+		}
+	}
+	if a, ok := param.tags["maxvalues"]; ok {
+		max, _ := strconv.Atoi(a)
+		count := value.Len()
+		if value.Type().Elem().Kind() == reflect.Slice {
+			count = 0
+			for iter := value.MapRange(); iter.Next(); {
+				count += iter.Value().Len()
+			}
+		}
+		if count > max {
+			return withTag(param.myFieldError("have at most "+a+" values"), "maxvalues", a)
+		}
+	}
+	return nil
+}
+
 // Validate tests if the param conforms to it's validation constraints specified
 // int the TAG_REGEXP struct tag
 func (param *Param) validateElem(value reflect.Value) (err error) {
@@ -158,49 +698,277 @@ func (param *Param) validateElem(value reflect.Value) (err error) {
 			err = fmt.Errorf("%v", p)
 		}
 	}()
+	var f64 float64
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f64 = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f64 = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		f64 = value.Float()
+	case reflect.Complex64, reflect.Complex128:
+		f64 = cmplx.Abs(value.Complex())
+	}
 	// range
 	if tuple, ok := param.tags["range"]; ok {
-		var f64 float64
-		switch value.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			f64 = float64(value.Int())
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			f64 = float64(value.Uint())
-		case reflect.Float32, reflect.Float64:
-			f64 = value.Float()
-		}
-		if err = validateRange(f64, tuple, param.name); err != nil {
-			return err
+		if err = validateRange(f64, tuple, param.name, param.isDuration); err != nil {
+			return withTag(err, "range", tuple)
 		}
 	}
+	// min, max, gt, lt
+	for _, k := range [...]string{"min", "max", "gt", "lt"} {
+		if bound, ok := param.tags[k]; ok {
+			if err = validateNumber(f64, k, bound, param.name, param.isDuration); err != nil {
+				return withTag(err, k, bound)
+			}
+		}
+	}
+	// sign
+	for k, bound := range signBounds {
+		if _, ok := param.tags[k]; ok {
+			if err = validateNumber(f64, bound[0], bound[1], param.name, param.isDuration); err != nil {
+				return withTag(err, k, "")
+			}
+		}
+	}
+	// geographic coordinates
+	if _, ok := param.tags["latitude"]; ok && (f64 < -90 || f64 > 90) {
+		return withTag(newFormatError("latitude", param.name), "latitude", "")
+	}
+	if _, ok := param.tags["longitude"]; ok && (f64 < -180 || f64 > 180) {
+		return withTag(newFormatError("longitude", param.name), "longitude", "")
+	}
 	obj := value.Interface()
+	// time bounds
+	if t, ok := obj.(time.Time); ok {
+		if err = param.validateTime(t); err != nil {
+			return err
+		}
+	}
 	// nonzero
 	if _, ok := param.tags["nonzero"]; ok {
-		if value.Kind() != reflect.Struct && obj == reflect.Zero(value.Type()).Interface() {
-			return NewValidationError(ValidationErrorValueNotSet, param.name)
+		var isZero bool
+		if t, isTime := obj.(time.Time); isTime {
+			isZero = t.IsZero()
+		} else {
+			// IsZero does not panic on the uncomparable kinds like map and func
+			isZero = value.Kind() != reflect.Struct && value.IsZero()
+		}
+		if isZero {
+			return withTag(NewValidationError(ValidationErrorValueNotSet, param.name), "nonzero", "")
 		}
 	}
-	s, isString := obj.(string)
+	var s string
+	var isString = value.Kind() == reflect.String
+	if isString {
+		s = value.String()
+	}
 	// length
 	if tuple, ok := param.tags["len"]; ok && isString {
 		if err = validateLen(s, tuple, param.name); err != nil {
-			return err
+			return withTag(err, "len", tuple)
 		}
 	}
 	// regexp
-	if reg, ok := param.tags[TAG_REGEXP]; ok && isString {
-		if err = validateRegexp(s, reg, param.name); err != nil {
-			return err
+	if param.regexp != nil && isString {
+		if err = validateRegexp(s, param.regexp, param.name); err != nil {
+			return withTag(err, TAG_REGEXP, param.tags[TAG_REGEXP])
+		}
+	}
+	// oneof
+	if list, ok := param.tags["oneof"]; ok {
+		if err = param.validateOneof(value, list); err != nil {
+			return withTag(err, "oneof", list)
+		}
+	}
+	// base64
+	if _, ok := param.tags["base64"]; ok && isString {
+		if err = validateBase64(s, base64.StdEncoding, "base64", param.name); err != nil {
+			return withTag(err, "base64", "")
+		}
+	}
+	if _, ok := param.tags["base64url"]; ok && isString {
+		if err = validateBase64(s, base64.URLEncoding, "base64url", param.name); err != nil {
+			return withTag(err, "base64url", "")
+		}
+	}
+	// semver
+	if constraints, ok := param.tags["semver"]; ok && isString {
+		if err = validateSemver(s, constraints, param.name); err != nil {
+			return withTag(err, "semver", constraints)
+		}
+	}
+	// checksum codes
+	if _, ok := param.tags["isbn"]; ok && isString && !isISBN(s) {
+		return withTag(newFormatError("isbn", param.name), "isbn", "")
+	}
+	if _, ok := param.tags["ean13"]; ok && isString && !isEAN13(s) {
+		return withTag(newFormatError("ean13", param.name), "ean13", "")
+	}
+	if _, ok := param.tags["ascii"]; ok && isString {
+		for i := 0; i < len(s); i++ {
+			if s[i] >= utf8.RuneSelf {
+				expect := fmt.Sprintf("be ASCII only, but has a non-ASCII byte at position %d", i)
+				return withTag(&ValidationError{kind: ValidationErrorValueNotMatch, field: param.name, expect: expect}, "ascii", "")
+			}
 		}
 	}
+	if _, ok := param.tags["email"]; ok && isString && !isEmail(s) {
+		return withTag(newFormatError("email", param.name), "email", "")
+	}
+	for _, k := range []string{"phone", "e164"} {
+		if _, ok := param.tags[k]; ok && isString && !isE164(s) {
+			return withTag(newFormatError("E.164 phone number", param.name), k, "")
+		}
+	}
+	if _, ok := param.tags["url"]; ok && isString && !isURL(s) {
+		return withTag(newFormatError("url", param.name), "url", "")
+	}
+	if _, ok := param.tags["uuid"]; ok && isString {
+		if _, ok = parseUUID(s); !ok {
+			return withTag(newFormatError("uuid", param.name), "uuid", "")
+		}
+	}
+	// constants
+	if param.eq.IsValid() && obj != param.eq.Interface() {
+		return withTag(param.myFieldError("equal "+param.tags["eq"]), "eq", param.tags["eq"])
+	}
+	if param.ne.IsValid() && obj == param.ne.Interface() {
+		return withTag(param.myFieldError("not equal "+param.tags["ne"]), "ne", param.tags["ne"])
+	}
+	// character sets
+	if param.allowChars != nil && isString {
+		for _, r := range s {
+			if !param.allowChars.has(r) {
+				return &ValidationError{kind: ValidationErrorValueNotMatch, field: param.name, expect: "only contain the characters `" + param.tags["charset"] + "`", rule: "charset", constraint: param.tags["charset"]}
+			}
+		}
+	}
+	if param.denyChars != nil && isString {
+		for _, r := range s {
+			if param.denyChars.has(r) {
+				return &ValidationError{kind: ValidationErrorValueNotMatch, field: param.name, expect: "not contain the character `" + string(r) + "`", rule: "denychars", constraint: param.tags["denychars"]}
+			}
+		}
+	}
+	// language tag
+	if _, ok := param.tags["bcp47"]; ok && isString && !isLanguageTag(s) {
+		return withTag(newFormatError("BCP 47 language tag", param.name), "bcp47", "")
+	}
+	// hostname
+	if _, ok := param.tags["hostname"]; ok && isString && !isHostname(s, false) {
+		return withTag(newFormatError("hostname", param.name), "hostname", "")
+	}
+	if _, ok := param.tags["fqdn"]; ok && isString && !isHostname(s, true) {
+		return withTag(newFormatError("fqdn", param.name), "fqdn", "")
+	}
 	return
 }
 
+// validateTime tests if the time is within the `after` and `before` bounds, both are exclusive.
+func (param *Param) validateTime(t time.Time) error {
+	if after, ok := param.tags["after"]; ok {
+		if !t.After(param.after) {
+			return withTag(NewValidationError(ValidationErrorValueTooSmall, param.name), "after", after)
+		}
+	}
+	if before, ok := param.tags["before"]; ok {
+		if !t.Before(param.before) {
+			return withTag(NewValidationError(ValidationErrorValueTooBig, param.name), "before", before)
+		}
+	}
+	return nil
+}
+
+// checkOneof tests if the `|` separated list of the `oneof` tag suits the field type,
+// the field must be a string or an integer, or a slice of them.
+func checkOneof(t reflect.Type, list string) error {
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var parse func(string) error
+	switch t.Kind() {
+	case reflect.String:
+		parse = func(string) error { return nil }
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parse = func(s string) error {
+			_, err := strconv.ParseInt(s, 10, t.Bits())
+			return err
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parse = func(s string) error {
+			_, err := strconv.ParseUint(s, 10, t.Bits())
+			return err
+		}
+	default:
+		return fmt.Errorf("not supported for type %s", t)
+	}
+	for _, v := range strings.Split(list, "|") {
+		if v == "" {
+			return fmt.Errorf("empty value in %q", list)
+		}
+		if err := parse(v); err != nil {
+			return fmt.Errorf("value %q is not a valid %s", v, t)
+		}
+	}
+	return nil
+}
+
+// validateOneof tests if the value is one of the `|` separated list,
+// when `ci` is set the comparison ignores case, and `canon` rewrites the value to the listed casing.
+func (param *Param) validateOneof(value reflect.Value, list string) error {
+	var s string
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		s = strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		s = strconv.FormatUint(value.Uint(), 10)
+	default:
+		s = value.String()
+	}
+	_, ci := param.tags["ci"]
+	for _, v := range strings.Split(list, "|") {
+		if s == v {
+			return nil
+		}
+		if ci && strings.EqualFold(s, v) {
+			if _, ok := param.tags["canon"]; ok && value.CanSet() {
+				value.SetString(v)
+			}
+			return nil
+		}
+	}
+	return NewValidationError(ValidationErrorValueNotAllowed, param.name)
+}
+
 func (param *Param) myError(reason string) error {
 	if param.err != nil {
 		return param.err
 	}
-	return NewError(param.apiName, param.name, reason)
+	return newBindError(param.apiName, param.name, reason)
+}
+
+func (param *Param) myValidationError(kind int) error {
+	if param.err != nil {
+		return param.err
+	}
+	return NewValidationError(kind, param.name)
+}
+
+// myKindError returns the error of the kind with the expectation, e.g. `at most 2mb`.
+func (param *Param) myKindError(kind int, expect string) error {
+	if param.err != nil {
+		return param.err
+	}
+	return &ValidationError{kind: kind, field: param.name, expect: expect}
+}
+
+// myFieldError returns the error of the cross-field expectation, e.g. `equal password`.
+func (param *Param) myFieldError(expect string) error {
+	if param.err != nil {
+		return param.err
+	}
+	return &ValidationError{kind: ValidationErrorValueNotMatch, field: param.name, expect: expect}
 }
 
 func parseTuple(tuple string) (string, string) {
@@ -247,35 +1015,367 @@ func validateLen(s, tuple, paramName string) error {
 
 const accuracy = 0.0000001
 
-func validateRange(f64 float64, tuple, paramName string) error {
+// numberBoundOps maps the numeric bound tags to their comparison operators.
+var numberBoundOps = map[string]string{
+	"min": ">=",
+	"max": "<=",
+	"gt":  ">",
+	"lt":  "<",
+}
+
+// signBounds maps the sign tags to their bound tags and values.
+var signBounds = map[string][2]string{
+	"positive":    {"gt", "0"},
+	"negative":    {"lt", "0"},
+	"nonnegative": {"min", "0"},
+}
+
+// compareNumber reports whether `f64 op bound` holds, within the accuracy.
+func compareNumber(f64 float64, op string, bound float64) bool {
+	equal := math.Abs(f64-bound) <= accuracy
+	switch op {
+	case ">=":
+		return equal || f64 > bound
+	case ">":
+		return !equal && f64 > bound
+	case "<=":
+		return equal || f64 < bound
+	case "<":
+		return !equal && f64 < bound
+	}
+	return false
+}
+
+// parseNumberBound parses the numeric bound, or the duration bound like `1h30m` in nanoseconds.
+func parseNumberBound(bound string, isDuration bool) (float64, error) {
+	if isDuration {
+		d, err := time.ParseDuration(bound)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(bound, 64)
+}
+
+// validateNumber tests f64 against the bound of the tag `min`, `max`, `gt` or `lt`,
+// the error reads like "page must be > 0".
+func validateNumber(f64 float64, tag, bound, paramName string, isDuration bool) error {
+	b, err := parseNumberBound(bound, isDuration)
+	if err != nil {
+		return err
+	}
+	op := numberBoundOps[tag]
+	if compareNumber(f64, op, b) {
+		return nil
+	}
+	kind := ValidationErrorValueTooSmall
+	if op[0] == '<' {
+		kind = ValidationErrorValueTooBig
+	}
+	return &ValidationError{kind: kind, field: paramName, expect: op + " " + bound}
+}
+
+func validateRange(f64 float64, tuple, paramName string, isDuration bool) error {
 	a, b := parseTuple(tuple)
 	if len(a) > 0 {
-		min, err := strconv.ParseFloat(a, 64)
+		min, err := parseNumberBound(a, isDuration)
 		if err != nil {
 			return err
 		}
-		if math.Min(f64, min) == f64 && math.Abs(f64-min) > accuracy {
+		if !compareNumber(f64, ">=", min) {
 			return NewValidationError(ValidationErrorValueTooSmall, paramName)
 		}
 	}
 	if len(b) > 0 {
-		max, err := strconv.ParseFloat(b, 64)
+		max, err := parseNumberBound(b, isDuration)
 		if err != nil {
 			return err
 		}
-		if math.Max(f64, max) == f64 && math.Abs(f64-max) > accuracy {
+		if !compareNumber(f64, "<=", max) {
 			return NewValidationError(ValidationErrorValueTooBig, paramName)
 		}
 	}
 	return nil
 }
 
-func validateRegexp(s, reg, paramName string) error {
-	matched, err := regexp.MatchString(reg, s)
-	if err != nil {
-		return err
+// isEmail tests if s is a bare email address like `gopher@example.com`, parsed by `net/mail`.
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// isE164 tests if s is an E.164 phone number like `+14155552671`,
+// a leading `+` and up to 15 digits without the leading zero.
+func isE164(s string) bool {
+	if len(s) < 3 || len(s) > 16 || s[0] != '+' || s[1] == '0' {
+		return false
 	}
-	if !matched {
+	for i := 1; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isURL tests if s is an absolute URL with the scheme and host, like `https://example.com/a?b=c`.
+func isURL(s string) bool {
+	u, err := url.ParseRequestURI(s)
+	return err == nil && len(u.Scheme) > 0 && len(u.Host) > 0
+}
+
+// parseUUID parses the hyphenated UUID like `123e4567-e89b-12d3-a456-426614174000`,
+// or the non-hyphenated one of 32 hex digits.
+func parseUUID(s string) ([16]byte, bool) {
+	var u [16]byte
+	switch len(s) {
+	case 36:
+		if s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+			return u, false
+		}
+		s = s[:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+	case 32:
+	default:
+		return u, false
+	}
+	if _, err := hex.Decode(u[:], []byte(s)); err != nil {
+		return u, false
+	}
+	return u, true
+}
+
+// isISBN tests if s is an ISBN-10 or ISBN-13 with the valid check digit,
+// the hyphens and spaces are ignored.
+func isISBN(s string) bool {
+	s = strings.NewReplacer("-", "", " ", "").Replace(s)
+	switch len(s) {
+	case 10:
+		// the weights are 10 to 1, and the check digit can be `X` as 10
+		var sum int
+		for i := 0; i < 10; i++ {
+			var d int
+			switch c := s[i]; {
+			case '0' <= c && c <= '9':
+				d = int(c - '0')
+			case i == 9 && (c == 'X' || c == 'x'):
+				d = 10
+			default:
+				return false
+			}
+			sum += d * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		return isEAN13(s)
+	}
+	return false
+}
+
+// isEAN13 tests if s is 13 digits with the valid check digit, the weights are alternately 1 and 3.
+func isEAN13(s string) bool {
+	if len(s) != 13 {
+		return false
+	}
+	var sum int
+	for i := 0; i < 13; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		if i%2 == 0 {
+			sum += int(c - '0')
+		} else {
+			sum += 3 * int(c-'0')
+		}
+	}
+	return sum%10 == 0
+}
+
+// isLanguageTag tests if s is a well-formed BCP 47 language tag, e.g. `en-US` or `zh-Hant-TW`,
+// per the RFC 5646 syntax: language[-script][-region]*(-variant)*(-extension)[-privateuse],
+// the subtags are case-insensitive, and the irregular grandfathered tags are not supported.
+func isLanguageTag(s string) bool {
+	subtags := strings.Split(s, "-")
+	for _, sub := range subtags {
+		if len(sub) == 0 || len(sub) > 8 || !isAlphanum(sub) {
+			return false
+		}
+	}
+	i := 0
+	next := func(ok func(string) bool) bool {
+		if i < len(subtags) && ok(subtags[i]) {
+			i++
+			return true
+		}
+		return false
+	}
+	alpha := func(min, max int) func(string) bool {
+		return func(s string) bool { return len(s) >= min && len(s) <= max && isAlpha(s) }
+	}
+	isPrivateUse := func(s string) bool { return strings.EqualFold(s, "x") }
+	if !isPrivateUse(subtags[0]) {
+		// language
+		if next(alpha(2, 3)) {
+			// extlang
+			for j := 0; j < 3 && next(alpha(3, 3)); j++ {
+			}
+		} else if !next(alpha(4, 8)) {
+			return false
+		}
+		// script
+		next(alpha(4, 4))
+		// region
+		if !next(alpha(2, 2)) {
+			next(func(s string) bool { return len(s) == 3 && isDigits(s) })
+		}
+		// variants
+		for next(func(s string) bool {
+			return len(s) >= 5 || len(s) == 4 && '0' <= s[0] && s[0] <= '9'
+		}) {
+		}
+		// extensions
+		for i < len(subtags) && len(subtags[i]) == 1 && !isPrivateUse(subtags[i]) {
+			i++
+			n := i
+			for next(func(s string) bool { return len(s) >= 2 }) {
+			}
+			if i == n {
+				return false
+			}
+		}
+	}
+	// private use
+	if i < len(subtags) && isPrivateUse(subtags[i]) {
+		i++
+		if i == len(subtags) {
+			return false
+		}
+		i = len(subtags)
+	}
+	return i == len(subtags)
+}
+
+func isAlpha(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i] | 0x20; c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isAlphanum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !('0' <= c && c <= '9') && !isAlpha(s[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isHostname tests if s is a hostname per the DNS rules: at most 253 characters,
+// and each dot separated label has 1 to 63 letters, digits or hyphens, not starting or ending with a hyphen.
+// When fqdn is true, s must have at least two labels and the top-level one can not be all digits,
+// a trailing dot is allowed.
+func isHostname(s string, fqdn bool) bool {
+	if fqdn {
+		s = strings.TrimSuffix(s, ".")
+	}
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+	labels := strings.Split(s, ".")
+	if fqdn && len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			c := label[i]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+	}
+	if fqdn {
+		tld := labels[len(labels)-1]
+		if strings.Trim(tld, "0123456789") == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// charSet is a set of characters, e.g. `a-zA-Z0-9_`,
+// the ASCII characters are looked up by index, and the others by ranges.
+type charSet struct {
+	ascii  [utf8.RuneSelf]bool
+	ranges [][2]rune
+}
+
+// parseCharSet parses the characters and the `a-z` like ranges, a leading or trailing `-` is a literal.
+func parseCharSet(list string) (*charSet, error) {
+	runes := []rune(list)
+	if len(runes) == 0 {
+		return nil, errors.New("empty character set")
+	}
+	set := new(charSet)
+	for i := 0; i < len(runes); i++ {
+		lo, hi := runes[i], runes[i]
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			hi = runes[i+2]
+			i += 2
+			if hi < lo {
+				return nil, fmt.Errorf("invalid range `%c-%c`", lo, hi)
+			}
+		}
+		for ; lo <= hi && lo < utf8.RuneSelf; lo++ {
+			set.ascii[lo] = true
+		}
+		if lo <= hi {
+			set.ranges = append(set.ranges, [2]rune{lo, hi})
+		}
+	}
+	return set, nil
+}
+
+// has tests if the set contains r.
+func (set *charSet) has(r rune) bool {
+	if r < utf8.RuneSelf {
+		return r >= 0 && set.ascii[r]
+	}
+	for _, rg := range set.ranges {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBase64 tests if s is encoded by enc, the padding can be omitted entirely.
+func validateBase64(s string, enc *base64.Encoding, rule, paramName string) error {
+	if _, err := enc.DecodeString(s); err == nil {
+		return nil
+	}
+	if !strings.HasSuffix(s, "=") {
+		if _, err := enc.WithPadding(base64.NoPadding).DecodeString(s); err == nil {
+			return nil
+		}
+	}
+	return newFormatError(rule, paramName)
+}
+
+func validateRegexp(s string, reg *regexp.Regexp, paramName string) error {
+	if !reg.MatchString(s) {
 		return NewValidationError(ValidationErrorValueNotMatch, paramName)
 	}
 	return nil
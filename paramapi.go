@@ -15,15 +15,26 @@
 package apiware
 
 import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
-	// "mime/multipart"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/valyala/fasthttp"
 )
@@ -43,8 +54,45 @@ type (
 		bodyDecodeFunc BodyDecodeFunc
 		//when request Content-Type is multipart/form-data, the max memory for body.
 		maxMemory int64
+		// the max size of the `body` param
+		maxBody int64
+		// validate the request body against an external JSON Schema
+		jsonSchemaValidateFunc JSONSchemaValidateFunc
+		// fail the registration on unknown `param` tag keys
+		strictTags bool
+		// decode the query and formData values from the client's charset to UTF-8
+		charsetDecodeFunc CharsetDecodeFunc
+		// pick the body decoder by the request's Content-Type
+		bodyDecoderMux BodyDecoderMux
+		// decode the body with the context of the binding, instead of bodyDecodeFunc
+		bodyDecodeContextFunc BodyDecodeContextFunc
+		// fail the indexed slice params with gaps, instead of filling zero values
+		strictSliceIndex bool
+		// stream the multipart files to the `FileHandler` params
+		streamFiles bool
+		// count the body bytes read for the `bodysize` params
+		countBody bool
+		// fail the path params not declared in the struct
+		strictPathParams bool
+		// the invalid bool params fall back to the defaults
+		lenientBool bool
+		// the positions of the declared params
+		hasBody, hasFormData, hasFile bool
+		// the released struct pointers for `BindPooled`
+		pool sync.Pool
+		// the embedded structs on the params' field paths, parents first
+		fieldNodes []fieldNode
 	}
 
+	// fieldNode is an embedded struct on the field paths, cached when registering
+	fieldNode struct {
+		parent int // the index of the parent node, -1 for the root struct
+		index  int // the field index in the parent
+	}
+
+	// Option configures the ParamsAPI when registering
+	Option func(*ParamsAPI)
+
 	// Schema is a collection of ParamsAPI
 	Schema struct {
 		lib map[string]*ParamsAPI
@@ -56,6 +104,18 @@ type (
 
 	// Decode params from request body
 	BodyDecodeFunc func(dest reflect.Value, body []byte) error
+
+	// Decode params from request body, observing the context of the binding
+	BodyDecodeContextFunc func(ctx context.Context, dest reflect.Value, body []byte) error
+
+	// BodyDecoderMux maps the media types to the body decoders, e.g. `application/xml` to `BodyXML`
+	BodyDecoderMux map[string]BodyDecodeFunc
+
+	// Validate the request body against the JSON Schema
+	JSONSchemaValidateFunc func(schema, body []byte) error
+
+	// Decode the value from the client's charset to UTF-8, e.g. by a GBK `encoding.Decoder` of `golang.org/x/text`
+	CharsetDecodeFunc func(s string) (string, error)
 )
 
 var (
@@ -64,6 +124,169 @@ var (
 	}
 )
 
+// StrictTags makes the registration fail on unknown `param` tag keys, e.g. the misspelled `requred`.
+func StrictTags() Option {
+	return func(m *ParamsAPI) {
+		m.strictTags = true
+	}
+}
+
+// StrictSliceIndex makes the indexed slice params with gaps fail, e.g. `items[0]=a&items[2]=c`,
+// by default the gaps are filled with zero values.
+func StrictSliceIndex() Option {
+	return func(m *ParamsAPI) {
+		m.strictSliceIndex = true
+	}
+}
+
+// LenientBool makes the invalid or empty values of the bool params fall back to their `default` tags,
+// or the zero values without the `default` tags, e.g. `?debug=maybe`,
+// by default the values other than the truthy words are `false`.
+func LenientBool() Option {
+	return func(m *ParamsAPI) {
+		m.lenientBool = true
+	}
+}
+
+// StrictPathParams makes the binding fail when the path params contain one not declared in the struct,
+// which catches the mismatches between the routes and the structs, the KV must implement `KeysKV`.
+func StrictPathParams() Option {
+	return func(m *ParamsAPI) {
+		m.strictPathParams = true
+	}
+}
+
+// checkPathParams tests if all the path params are declared in the struct, for the `StrictPathParams` option.
+func (paramsAPI *ParamsAPI) checkPathParams(pathParams KV) error {
+	kv, ok := pathParams.(KeysKV)
+	if !ok {
+		return newBindError(paramsAPI.name, "?", "the path params can not be listed, the KV must implement KeysKV")
+	}
+	declared := make(map[string]bool)
+	for _, param := range paramsAPI.params {
+		if param.In() != "path" {
+			continue
+		}
+		if param.rawValue.Type() == stringMapType {
+			// the catch-all path param declares all
+			return nil
+		}
+		declared[param.name] = true
+	}
+	for _, key := range kv.Keys() {
+		if !declared[key] {
+			return newBindError(paramsAPI.name, key, "undeclared path param")
+		}
+	}
+	return nil
+}
+
+// maxSliceIndex limits the indexed slice params, so that `items[999999999]` can not allocate too much.
+const maxSliceIndex = 1024
+
+// assignIndexed assigns the indexed values like `items[2]=c` to the slice value,
+// the gaps are zero values, or an error with the `StrictSliceIndex` option.
+func (paramsAPI *ParamsAPI) assignIndexed(ctx context.Context, param *Param, value reflect.Value, indexed map[int]string) error {
+	max := -1
+	for i := range indexed {
+		if i > max {
+			max = i
+		}
+	}
+	if max >= maxSliceIndex {
+		return fmt.Errorf("index %d out of range, it must be less than %d", max, maxSliceIndex)
+	}
+	if paramsAPI.strictSliceIndex {
+		for i := 0; i <= max; i++ {
+			if _, ok := indexed[i]; !ok {
+				return fmt.Errorf("missing index %d", i)
+			}
+		}
+	}
+	slice := reflect.MakeSlice(value.Type(), max+1, max+1)
+	for i, s := range indexed {
+		src, err := paramsAPI.decodeCharset([]string{s})
+		if err != nil {
+			return err
+		}
+		if err = param.assign(ctx, slice.Index(i), src); err != nil {
+			return fmt.Errorf("index %d: %v", i, err)
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+// Charset decodes the query and formData values by fn before assigning them,
+// for the legacy clients sending non-UTF-8 forms.
+func Charset(fn CharsetDecodeFunc) Option {
+	return func(m *ParamsAPI) {
+		m.charsetDecodeFunc = fn
+	}
+}
+
+// BodyMux picks the body decoder from mux by the media type of the request's Content-Type,
+// and falls back to the `bodyDecodeFunc` of the registration when the media type is not in mux.
+func BodyMux(mux BodyDecoderMux) Option {
+	return func(m *ParamsAPI) {
+		m.bodyDecoderMux = mux
+	}
+}
+
+// BodyDecodeContext decodes the body by fn instead of the `bodyDecodeFunc` of the registration,
+// fn receives the context of the binding, which is the request's context for the net/http bindings,
+// ctx of `BindFieldsContext`, and `context.Background()` for the fasthttp bindings.
+// The decoders of `BodyMux` still take precedence for their media types.
+func BodyDecodeContext(fn BodyDecodeContextFunc) Option {
+	return func(m *ParamsAPI) {
+		m.bodyDecodeContextFunc = fn
+	}
+}
+
+// decodeBody decodes the body by the decoder for the contentType, a `[]byte` dest receives the raw body.
+func (paramsAPI *ParamsAPI) decodeBody(ctx context.Context, contentType string, dest reflect.Value, body []byte) error {
+	if dest.Type() == bytesType {
+		// the raw body passthrough, copied since the fasthttp body buffer is reused
+		dest.SetBytes(append([]byte{}, body...))
+		return nil
+	}
+	if dest.Kind() == reflect.Ptr {
+		// the optional struct pointer body
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		dest = dest.Elem()
+	}
+	if len(paramsAPI.bodyDecoderMux) > 0 {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil {
+			if fn, ok := paramsAPI.bodyDecoderMux[mediaType]; ok {
+				return fn(dest, body)
+			}
+		}
+	}
+	if paramsAPI.bodyDecodeContextFunc != nil {
+		return paramsAPI.bodyDecodeContextFunc(ctx, dest, body)
+	}
+	return paramsAPI.bodyDecodeFunc(dest, body)
+}
+
+// decodeCharset decodes the values by the `Charset` option, if any.
+func (paramsAPI *ParamsAPI) decodeCharset(values []string) ([]string, error) {
+	if paramsAPI.charsetDecodeFunc == nil {
+		return values, nil
+	}
+	decoded := make([]string, len(values))
+	for i, v := range values {
+		s, err := paramsAPI.charsetDecodeFunc(v)
+		if err != nil {
+			return nil, err
+		}
+		decoded[i] = s
+	}
+	return decoded, nil
+}
+
 // NewParamsAPI parses and store the struct object, requires a struct pointer,
 // if `paramNameFunc` is nil, `paramNameFunc=toSnake`,
 // if `bodyDecodeFunc` is nil, `bodyDecodeFunc=bodyJONS`,
@@ -71,6 +294,7 @@ func NewParamsAPI(
 	structPointer interface{},
 	paramNameFunc ParamNameFunc,
 	bodyDecodeFunc BodyDecodeFunc,
+	options ...Option,
 ) (
 	*ParamsAPI,
 	error,
@@ -100,10 +324,25 @@ func NewParamsAPI(
 	} else {
 		m.bodyDecodeFunc = bodyJONS
 	}
+	for _, option := range options {
+		option(m)
+	}
 	err := m.addFields([]int{}, m.structType, v)
 	if err != nil {
 		return nil, err
 	}
+	if err = m.checkFieldRefs(); err != nil {
+		return nil, err
+	}
+	m.cacheFieldNodes()
+	for _, param := range m.params {
+		m.hasBody = m.hasBody || param.In() == "body"
+		m.hasFormData = m.hasFormData || param.In() == "formData"
+		m.hasFile = m.hasFile || param.isFile
+		for _, source := range param.sources {
+			m.hasFormData = m.hasFormData || source.in == "formData"
+		}
+	}
 	defaultSchema.set(m)
 	return m, nil
 }
@@ -116,11 +355,29 @@ func Register(
 	structPointer interface{},
 	paramNameFunc ParamNameFunc,
 	bodyDecodeFunc BodyDecodeFunc,
+	options ...Option,
 ) error {
-	_, err := NewParamsAPI(structPointer, paramNameFunc, bodyDecodeFunc)
+	_, err := NewParamsAPI(structPointer, paramNameFunc, bodyDecodeFunc, options...)
 	return err
 }
 
+// MustRegister is similar to a `Register`, but returns the `*ParamsAPI`, and panics on error.
+func MustRegister(
+	structPointer interface{},
+	paramNameFunc ParamNameFunc,
+	bodyDecodeFunc BodyDecodeFunc,
+	options ...Option,
+) *ParamsAPI {
+	m, err := NewParamsAPI(structPointer, paramNameFunc, bodyDecodeFunc, options...)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// maxEmbeddedDepth limits the embedded struct pointers, e.g. the recursive `type A struct{ *A }`.
+const maxEmbeddedDepth = 32
+
 func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.Value) error {
 	var err error
 	var maxMemoryMB int64
@@ -134,28 +391,80 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 		var field = t.Field(i)
 		tag, ok := field.Tag.Lookup(TAG_PARAM)
 		if !ok {
-			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			// only the untagged anonymous struct or struct pointer extends the params,
+			// the other untagged fields, including the anonymous non-struct ones, are ignored
+			if !field.Anonymous {
+				continue
+			}
+			switch {
+			case field.Type.Kind() == reflect.Struct:
 				if err = m.addFields(indexPath, field.Type, v.Field(i)); err != nil {
 					return err
 				}
+			case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct:
+				if deep >= maxEmbeddedDepth {
+					return NewError(t.String(), field.Name, "the embedded struct is too deep or recursive")
+				}
+				fv := v.Field(i)
+				if !fv.CanSet() {
+					return NewError(t.String(), field.Name, "the embedded struct pointer must be exported")
+				}
+				// the caller's nil pointer is left untouched, it is allocated when binding
+				ev := reflect.New(field.Type.Elem()).Elem()
+				if !fv.IsNil() {
+					ev = fv.Elem()
+				}
+				if err = m.addFields(indexPath, field.Type.Elem(), ev); err != nil {
+					return err
+				}
 			}
 			continue
 		}
 
-		if tag == TAG_IGNORE_PARAM {
+		if tag == TAG_IGNORE_PARAM || tag == TAG_SKIP_PARAM {
 			continue
 		}
 
-		if field.Type.Kind() == reflect.Ptr {
-			return NewError(t.String(), field.Name, "field can not be a pointer")
+		if len(field.PkgPath) > 0 {
+			return NewError(t.String(), field.Name, "field with the `"+TAG_PARAM+"` tag must be exported")
+		}
+
+		if field.Type.Kind() == reflect.Ptr && !isBaseKind(field.Type.Elem().Kind()) &&
+			(field.Type.Elem().Kind() != reflect.Struct || ParseTags(tag)["in"] != "body") {
+			return NewError(t.String(), field.Name, "field can not be a pointer, except for the optional base type, e.g. `*int` or `*string`, and the `body` struct pointer")
 		}
 
 		var parsedTags = ParseTags(tag)
+		if m.strictTags {
+			var keys []string
+			for k := range parsedTags {
+				if !paramTagKeys[k] {
+					keys = append(keys, k)
+				}
+			}
+			if len(keys) > 0 {
+				sort.Strings(keys)
+				return NewError(t.String(), field.Name, "unknown `"+TAG_PARAM+"` tag key `"+strings.Join(keys, "`, `")+"`")
+			}
+		}
+		var sources []paramSource
+		if list, ok := parsedTags["fallback"]; ok {
+			if sources, err = parseSources(list); err != nil {
+				return NewError(t.String(), field.Name, "invalid `fallback` tag: "+err.Error())
+			}
+			if _, ok = parsedTags["in"]; !ok {
+				parsedTags["in"] = sources[0].in
+			}
+		}
 		var paramPosition = parsedTags["in"]
 		var paramTypeString = field.Type.String()
+		if field.Type.Kind() == reflect.Ptr {
+			// the optional base type is checked like its element
+			paramTypeString = field.Type.Elem().String()
+		}
 
 		switch paramTypeString {
-		case fileTypeString:
+		case fileTypeString, fileMapTypeString, filesTypeString, files2TypeString, fileHandlerTypeString:
 			if paramPosition != "formData" {
 				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `formData`")
 			}
@@ -163,6 +472,30 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 			if paramPosition != "cookie" {
 				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `cookie`")
 			}
+		case keyValuesTypeString:
+			if paramPosition != "query" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `query`")
+			}
+		case stringMapTypeString:
+			if paramPosition != "query" && paramPosition != "body" && paramPosition != "path" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `query`, `body` or `path`")
+			}
+		case stringsMapTypeString:
+			if paramPosition != "query" && paramPosition != "body" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `query` or `body`")
+			}
+		case urlValuesTypeString:
+			if paramPosition != "query" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `query`")
+			}
+		case readerTypeString:
+			if paramPosition != "body" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `body`")
+			}
+		case authTypeString:
+			if paramPosition != "auth" {
+				return NewError(t.String(), field.Name, "when field type is `"+paramTypeString+"`, tag `in` value must be `auth`")
+			}
 		}
 
 		switch paramPosition {
@@ -189,18 +522,122 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 		// 	}
 		default:
 			if !TagInValues[paramPosition] {
-				return NewError(t.String(), field.Name, "invalid tag `in` value, refer to the following: `path`, `query`, `formData`, `body`, `header` or `cookie`")
+				return NewError(t.String(), field.Name, "invalid tag `in` value, refer to the following: `path`, `query`, `formData`, `body`, `header`, `cookie`, `host`, `scheme`, `auth` or `bodysize`")
 			}
 		}
+		if _, ok := parsedTags["nonempty"]; ok && (!isFileTypeString(paramTypeString) || paramTypeString == fileHandlerTypeString) {
+			return NewError(t.String(), field.Name, "invalid `nonempty` tag for non-file or streamed file field")
+		}
+		if (paramPosition == "host" || paramPosition == "scheme") && field.Type.Kind() != reflect.String {
+			return NewError(t.String(), field.Name, "when tag `in` value is `"+paramPosition+"`, field type must be `string`")
+		}
+		if paramPosition == "auth" && field.Type != authorizationType {
+			return NewError(t.String(), field.Name, "when tag `in` value is `auth`, field type must be `apiware.Authorization`")
+		}
+		if paramPosition == "bodysize" {
+			switch field.Type.Kind() {
+			case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+			default:
+				return NewError(t.String(), field.Name, "when tag `in` value is `bodysize`, field type must be `int`, `int32`, `int64`, `uint`, `uint32` or `uint64`")
+			}
+			m.countBody = true
+		}
+		if sources != nil && (paramPosition == "body" || isFileTypeString(paramTypeString)) {
+			return NewError(t.String(), field.Name, "the `fallback` tag can not be used for body or file param")
+		}
 		if _, ok := parsedTags["len"]; ok && paramTypeString != "string" && paramTypeString != "[]string" {
 			return NewError(t.String(), field.Name, "invalid `len` tag for non-string field")
 		}
-		if _, ok := parsedTags["range"]; ok {
+		for _, k := range []string{"base64", "base64url", "sanitize", "hostname", "fqdn", "semver", "isbn", "ean13", "charset", "denychars", "trim", "lower", "upper", "bcp47", "uuid", "email", "url", "ascii", "phone", "e164"} {
+			if _, ok := parsedTags[k]; ok && paramTypeString != "string" && paramTypeString != "[]string" {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag for non-string field")
+			}
+		}
+		if list, ok := parsedTags["oneof"]; ok {
+			if err := checkOneof(field.Type, list); err != nil {
+				return NewError(t.String(), field.Name, "invalid `oneof` tag: "+err.Error())
+			}
+		}
+		for _, k := range []string{"ci", "canon"} {
+			if _, ok := parsedTags[k]; ok {
+				if _, ok = parsedTags["oneof"]; !ok {
+					return NewError(t.String(), field.Name, "the `"+k+"` tag requires the `oneof` tag")
+				}
+			}
+		}
+		for _, k := range []string{"time", "after", "before"} {
+			if _, ok := parsedTags[k]; ok && field.Type != timeType && field.Type != timesType {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag for non-`time.Time` field")
+			}
+		}
+		var timeBounds [2]time.Time // the parsed `after` and `before` tags
+		for i, k := range []string{"after", "before"} {
+			if bound, ok := parsedTags[k]; ok {
+				layout, ok := parsedTags["time"]
+				if !ok {
+					layout = time.RFC3339
+				}
+				if timeBounds[i], err = time.Parse(layout, bound); err != nil {
+					return NewError(t.String(), field.Name, "invalid `"+k+"` tag: "+err.Error())
+				}
+			}
+		}
+		if sep, ok := parsedTags["split"]; ok {
+			if len(sep) == 0 {
+				return NewError(t.String(), field.Name, "invalid `split` tag, the separator can not be empty")
+			}
+			if field.Type.Kind() != reflect.Slice || field.Type == bytesType {
+				return NewError(t.String(), field.Name, "invalid `split` tag for non-slice field")
+			}
+		}
+		if _, ok := parsedTags["presence"]; ok && field.Type.Kind() != reflect.Bool {
+			return NewError(t.String(), field.Name, "invalid `presence` tag for non-bool field")
+		}
+		if _, ok := parsedTags["sortfields"]; ok && field.Type != sortFieldsType {
+			return NewError(t.String(), field.Name, "invalid `sortfields` tag for non-`[]apiware.SortField` field")
+		}
+		isDuration := paramTypeString == "time.Duration" || paramTypeString == "[]time.Duration"
+		for _, k := range []string{"range", "latitude", "longitude", "min", "max", "gt", "lt", "positive", "negative", "nonnegative"} {
+			if _, ok := parsedTags[k]; !ok {
+				continue
+			}
 			switch paramTypeString {
 			case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
 			case "[]int", "[]int8", "[]int16", "[]int32", "[]int64", "[]uint", "[]uint8", "[]uint16", "[]uint32", "[]uint64", "[]float32", "[]float64":
+			case "time.Duration", "[]time.Duration":
+				// the bounds are durations, e.g. `range(1s:1h)`
+				if k == "latitude" || k == "longitude" {
+					return NewError(t.String(), field.Name, "invalid `"+k+"` tag for `time.Duration` field")
+				}
+			case "complex64", "complex128", "[]complex64", "[]complex128":
+				// the bounds are checked against the magnitude
+				if _, ok := numberBoundOps[k]; !ok && k != "range" {
+					return NewError(t.String(), field.Name, "invalid `"+k+"` tag for complex field")
+				}
 			default:
-				return NewError(t.String(), field.Name, "invalid `range` tag for non-number field")
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag for non-number field")
+			}
+		}
+		if constraints, ok := parsedTags["semver"]; ok {
+			if _, err := parseSemverConstraints(constraints); err != nil {
+				return NewError(t.String(), field.Name, "invalid `semver` tag: "+err.Error())
+			}
+		}
+		for k := range numberBoundOps {
+			if bound, ok := parsedTags[k]; ok {
+				if _, err := parseNumberBound(bound, isDuration); err != nil && isDuration {
+					return NewError(t.String(), field.Name, "invalid `"+k+"` tag: "+bound+" is not a duration")
+				} else if err != nil {
+					return NewError(t.String(), field.Name, "invalid `"+k+"` tag: "+bound+" is not a number")
+				}
+			}
+		}
+		if tuple, ok := parsedTags["range"]; ok && isDuration {
+			a, b := parseTuple(tuple)
+			for _, bound := range []string{a, b} {
+				if _, err := parseNumberBound(bound, true); len(bound) > 0 && err != nil {
+					return NewError(t.String(), field.Name, "invalid `range` tag: "+bound+" is not a duration")
+				}
 			}
 		}
 		if a, ok := field.Tag.Lookup(TAG_REGEXP); ok {
@@ -218,13 +655,30 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 				maxMemoryMB = i
 			}
 		}
+		if a, ok := parsedTags["maxbody"]; ok {
+			if paramPosition != "body" {
+				return NewError(t.String(), field.Name, "the `maxbody` tag can only be used for body param")
+			}
+			i, err := strconv.ParseInt(a, 10, 64)
+			if err != nil || i <= 0 {
+				return NewError(t.String(), field.Name, "invalid `maxbody` tag, it must be positive integer")
+			}
+			m.maxBody = i * MB
+		}
 
 		fd := &Param{
-			apiName:   m.name,
-			indexPath: indexPath,
-			tags:      parsedTags,
-			rawTag:    field.Tag,
-			rawValue:  v.Field(i),
+			apiName:    m.name,
+			fieldName:  field.Name,
+			indexPath:  indexPath,
+			tags:       parsedTags,
+			rawTag:     field.Tag,
+			rawValue:   v.Field(i),
+			isDuration: isDuration,
+			after:      timeBounds[0],
+			before:     timeBounds[1],
+		}
+		if m.lenientBool && (field.Type.Kind() == reflect.Bool || field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Bool) {
+			fd.lenientBool = true
 		}
 
 		if errStr, ok := field.Tag.Lookup(TAG_ERR); ok {
@@ -238,8 +692,167 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 			fd.name = m.paramNameFunc(field.Name)
 		}
 
-		fd.isFile = paramTypeString == fileTypeString
+		for i := range sources {
+			if len(sources[i].name) == 0 {
+				sources[i].name = fd.name
+			}
+		}
+		fd.sources = sources
+
+		fd.isFile = isFileTypeString(paramTypeString)
+		if fd.isFile {
+			for _, p := range m.params {
+				if p.isFile && (p.rawValue.Type() == fileHandlerType) != (field.Type == fileHandlerType) {
+					return NewError(t.String(), field.Name, "the `apiware.FileHandler` param can not be used with the buffered file params")
+				}
+			}
+			m.streamFiles = m.streamFiles || field.Type == fileHandlerType
+		}
 		_, fd.isRequired = parsedTags["required"]
+		if list, ok := parsedTags["required_methods"]; ok {
+			if fd.isRequired {
+				return NewError(t.String(), field.Name, "the `required_methods` tag can not be used with the `required` tag")
+			}
+			for _, method := range strings.Split(list, ",") {
+				fd.requiredMethods = append(fd.requiredMethods, strings.ToUpper(strings.TrimSpace(method)))
+			}
+		}
+		_, fd.noValidate = parsedTags["novalidate"]
+		if _, fd.readonly = parsedTags["readonly"]; fd.readonly {
+			switch paramPosition {
+			case "query", "formData", "header", "cookie":
+			default:
+				return NewError(t.String(), field.Name, "the `readonly` tag can only be used for `query`, `formData`, `header` or `cookie` param")
+			}
+			if fd.isRequired || fd.requiredMethods != nil || fd.isFile {
+				return NewError(t.String(), field.Name, "the `readonly` tag can not be used for required or file param")
+			}
+			if _, ok := parsedTags["default"]; ok {
+				return NewError(t.String(), field.Name, "the `readonly` tag can not be used with the `default` tag")
+			}
+		}
+		if _, ok := parsedTags["lower"]; ok {
+			if _, ok = parsedTags["upper"]; ok {
+				return NewError(t.String(), field.Name, "the `lower` tag can not be used with the `upper` tag")
+			}
+		}
+		var normalizers []string
+		for _, k := range []string{"trim", "lower", "upper"} {
+			if _, ok := parsedTags[k]; ok {
+				normalizers = append(normalizers, k)
+			}
+		}
+		if len(normalizers) > 0 {
+			if fd.normalizers, err = parseTransforms(strings.Join(normalizers, "|")); err != nil {
+				return NewError(t.String(), field.Name, "invalid `"+strings.Join(normalizers, "`, `")+"` tag: "+err.Error())
+			}
+		}
+		if a, ok := parsedTags["maxsize"]; ok {
+			if !fd.isFile || field.Type == fileHandlerType {
+				return NewError(t.String(), field.Name, "the `maxsize` tag can only be used for buffered file param")
+			}
+			if fd.maxSize, err = parseSize(a); err != nil {
+				return NewError(t.String(), field.Name, "invalid `maxsize` tag: "+err.Error())
+			}
+		}
+		if a, ok := parsedTags["maxtotalmb"]; ok {
+			switch paramTypeString {
+			case filesTypeString, files2TypeString, fileMapTypeString:
+			default:
+				return NewError(t.String(), field.Name, "the `maxtotalmb` tag can only be used for `[]*multipart.FileHeader`, `[]multipart.FileHeader` or `map[string]*multipart.FileHeader` field")
+			}
+			i, err := strconv.ParseInt(a, 10, 64)
+			if err != nil || i <= 0 {
+				return NewError(t.String(), field.Name, "invalid `maxtotalmb` tag, it must be positive integer")
+			}
+			fd.maxTotalSize = i * MB
+		}
+		if _, ok := parsedTags["accept"]; ok && !fd.isFile {
+			return NewError(t.String(), field.Name, "the `accept` tag can only be used for file param")
+		}
+		if reg, ok := parsedTags[TAG_REGEXP]; ok {
+			if fd.regexp, err = regexp.Compile(reg); err != nil {
+				return NewError(t.String(), field.Name, "invalid `"+TAG_REGEXP+"` tag: "+err.Error())
+			}
+		}
+		if a, ok := parsedTags["endian"]; ok {
+			switch a {
+			case "big":
+				fd.byteOrder = binary.BigEndian
+			case "little":
+				fd.byteOrder = binary.LittleEndian
+			default:
+				return NewError(t.String(), field.Name, "invalid `endian` tag, it must be `big` or `little`")
+			}
+			if !isEndianType(field.Type) {
+				return NewError(t.String(), field.Name, "the `endian` tag can only be used for integer field, its pointer or slice")
+			}
+		}
+		if list, ok := parsedTags["parsers"]; ok {
+			if fd.parsers, err = parseTransforms(list); err != nil {
+				return NewError(t.String(), field.Name, "invalid `parsers` tag: "+err.Error())
+			}
+		}
+		if a, ok := parsedTags["maxdepth"]; ok {
+			if paramPosition != "body" {
+				return NewError(t.String(), field.Name, "the `maxdepth` tag can only be used for body param")
+			}
+			if fd.maxDepth, err = strconv.Atoi(a); err != nil || fd.maxDepth <= 0 {
+				return NewError(t.String(), field.Name, "invalid `maxdepth` tag, it must be positive integer")
+			}
+		}
+		for _, k := range []string{"maxkeys", "maxvalues"} {
+			a, ok := parsedTags[k]
+			if !ok {
+				continue
+			}
+			if field.Type.Kind() != reflect.Map {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag for non-map field")
+			}
+			if i, err := strconv.Atoi(a); err != nil || i <= 0 {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag, it must be positive integer")
+			}
+		}
+		if list, ok := parsedTags["charset"]; ok {
+			if fd.allowChars, err = parseCharSet(list); err != nil {
+				return NewError(t.String(), field.Name, "invalid `charset` tag: "+err.Error())
+			}
+		}
+		if list, ok := parsedTags["denychars"]; ok {
+			if fd.denyChars, err = parseCharSet(list); err != nil {
+				return NewError(t.String(), field.Name, "invalid `denychars` tag: "+err.Error())
+			}
+		}
+		for k, dest := range map[string]*reflect.Value{"eq": &fd.eq, "ne": &fd.ne} {
+			lit, ok := parsedTags[k]
+			if !ok {
+				continue
+			}
+			elemType := field.Type
+			if elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			if !isBaseKind(elemType.Kind()) {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag for non-string, non-number or non-bool field")
+			}
+			*dest = reflect.New(elemType).Elem()
+			if err = convertAssign(context.Background(), *dest, []string{lit}); err != nil {
+				return NewError(t.String(), field.Name, "invalid `"+k+"` tag: "+err.Error())
+			}
+		}
+
+		if def, ok := parsedTags["default"]; ok {
+			if fd.isRequired || paramPosition == "body" || fd.isFile {
+				return NewError(t.String(), field.Name, "the `default` tag can not be used for required, body or file param")
+			}
+			fd.defaults = []string{def}
+			if field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() != reflect.Uint8 {
+				fd.defaults = strings.Split(def, ",")
+			}
+			if err := fd.assign(context.Background(), reflect.New(field.Type), fd.defaults); err != nil {
+				return NewError(t.String(), field.Name, "invalid `default` tag: "+err.Error())
+			}
+		}
 
 		// err = fd.validate(v)
 		// if err != nil {
@@ -253,6 +866,119 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 	} else {
 		m.maxMemory = defaultMaxMemory
 	}
+	if m.maxBody == 0 {
+		m.maxBody = defaultMaxBody
+	}
+	return nil
+}
+
+// parseFieldValue parses the `Field,value` condition, the value can be empty.
+func parseFieldValue(cond string) (fieldName, value string, ok bool) {
+	i := strings.IndexByte(cond, ',')
+	if i <= 0 {
+		return "", "", false
+	}
+	return strings.TrimSpace(cond[:i]), strings.TrimSpace(cond[i+1:]), true
+}
+
+// fieldEquals tests if the field of the `Field,value` condition is formatted as the value.
+func (paramsAPI *ParamsAPI) fieldEquals(fields []reflect.Value, cond string) bool {
+	fieldName, value, _ := parseFieldValue(cond)
+	field := fields[paramsAPI.paramIndex(fieldName)]
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return value == ""
+		}
+		field = field.Elem()
+	}
+	return fmt.Sprint(field.Interface()) == value
+}
+
+// checkFieldRefs checks that the struct fields referenced by the cross-field tags are params.
+func (m *ParamsAPI) checkFieldRefs() error {
+	for _, param := range m.params {
+		if list, ok := param.tags["required_with"]; ok {
+			for _, fieldName := range strings.Split(list, ",") {
+				if m.paramIndex(strings.TrimSpace(fieldName)) == -1 {
+					return NewError(m.name, param.fieldName, "invalid `required_with` tag, field `"+fieldName+"` is not a param")
+				}
+			}
+		}
+		for _, k := range []string{"required_if", "required_unless"} {
+			cond, ok := param.tags[k]
+			if !ok {
+				continue
+			}
+			fieldName, _, ok := parseFieldValue(cond)
+			if !ok {
+				return NewError(m.name, param.fieldName, "invalid `"+k+"` tag, it must be like `Field,value`")
+			}
+			if m.paramIndex(fieldName) == -1 {
+				return NewError(m.name, param.fieldName, "invalid `"+k+"` tag, field `"+fieldName+"` is not a param")
+			}
+		}
+		for _, k := range []string{"eqfield", "nefield"} {
+			fieldName, ok := param.tags[k]
+			if !ok {
+				continue
+			}
+			j := m.paramIndex(fieldName)
+			if j == -1 {
+				return NewError(m.name, param.fieldName, "invalid `"+k+"` tag, field `"+fieldName+"` is not a param")
+			}
+			if param.rawValue.Type() != m.params[j].rawValue.Type() {
+				return NewError(m.name, param.fieldName, "invalid `"+k+"` tag, field `"+fieldName+"` is not the same type")
+			}
+		}
+	}
+	return nil
+}
+
+// paramIndex returns the index of the param whose struct field name is fieldName, or -1.
+func (m *ParamsAPI) paramIndex(fieldName string) int {
+	for i, param := range m.params {
+		if param.fieldName == fieldName {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateFields validates the rules which depend on other fields, after all params are bound.
+// note: a field is regarded as present when its value is not the zero value.
+func (paramsAPI *ParamsAPI) validateFields(fields []reflect.Value) error {
+	for i, param := range paramsAPI.params {
+		if param.noValidate {
+			continue
+		}
+		if list, ok := param.tags["required_with"]; ok && fields[i].IsZero() {
+			for _, fieldName := range strings.Split(list, ",") {
+				if !fields[paramsAPI.paramIndex(strings.TrimSpace(fieldName))].IsZero() {
+					return withTag(param.myValidationError(ValidationErrorValueNotSet), "required_with", list)
+				}
+			}
+		}
+		if fields[i].IsZero() {
+			if cond, ok := param.tags["required_if"]; ok && paramsAPI.fieldEquals(fields, cond) {
+				return param.myError("missing required param")
+			}
+			if cond, ok := param.tags["required_unless"]; ok && !paramsAPI.fieldEquals(fields, cond) {
+				return param.myError("missing required param")
+			}
+		}
+		if fieldName, ok := param.tags["eqfield"]; ok {
+			j := paramsAPI.paramIndex(fieldName)
+			if !reflect.DeepEqual(fields[i].Interface(), fields[j].Interface()) {
+				return withTag(param.myFieldError("equal "+paramsAPI.params[j].name), "eqfield", fieldName)
+			}
+		}
+		if fieldName, ok := param.tags["nefield"]; ok {
+			j := paramsAPI.paramIndex(fieldName)
+			if reflect.DeepEqual(fields[i].Interface(), fields[j].Interface()) {
+				return withTag(param.myFieldError("not equal "+paramsAPI.params[j].name), "nefield", fieldName)
+			}
+		}
+	}
 	return nil
 }
 
@@ -260,11 +986,29 @@ func (m *ParamsAPI) addFields(parentIndexPath []int, t reflect.Type, v reflect.V
 func GetParamsAPI(paramsAPIName string) (*ParamsAPI, error) {
 	m, ok := defaultSchema.get(paramsAPIName)
 	if !ok {
-		return nil, errors.New("struct `" + paramsAPIName + "` is not registered")
+		return nil, NewError(paramsAPIName, "*", "the struct is not registered")
 	}
 	return m, nil
 }
 
+// WarmUp prepares the bindings of all the registered structs, see `(*ParamsAPI).WarmUp`.
+func WarmUp() {
+	defaultSchema.RLock()
+	defer defaultSchema.RUnlock()
+	for _, m := range defaultSchema.lib {
+		m.WarmUp()
+	}
+}
+
+// WarmUp resolves the converters and `Set(string) error` of the params, to cut the latency of the first requests,
+// it should be called after the converters are registered, since they are resolved again if changed.
+// note: the regexps are already compiled when registering.
+func (paramsAPI *ParamsAPI) WarmUp() {
+	for _, param := range paramsAPI.params {
+		param.prepare()
+	}
+}
+
 // SetParamsAPI caches `*ParamsAPI`
 func SetParamsAPI(m *ParamsAPI) {
 	defaultSchema.set(m)
@@ -293,6 +1037,24 @@ func (paramsAPI *ParamsAPI) Params() []*Param {
 	return paramsAPI.params
 }
 
+// ParamInfos returns the metadata of the params in order, e.g. for generating the API documents.
+func (paramsAPI *ParamsAPI) ParamInfos() []ParamInfo {
+	infos := make([]ParamInfo, len(paramsAPI.params))
+	for i, param := range paramsAPI.params {
+		infos[i] = param.Info()
+	}
+	return infos
+}
+
+// Describe returns the `desc` tag of each param by the param name, it is empty if the tag is not set.
+func (paramsAPI *ParamsAPI) Describe() map[string]string {
+	descs := make(map[string]string, len(paramsAPI.params))
+	for _, param := range paramsAPI.params {
+		descs[param.name] = param.Description()
+	}
+	return descs
+}
+
 // Number returns the number of parameters to be bound
 func (paramsAPI *ParamsAPI) Number() int {
 	return len(paramsAPI.params)
@@ -303,6 +1065,21 @@ func (paramsAPI *ParamsAPI) Raw() interface{} {
 	return paramsAPI.rawStructPointer
 }
 
+// HasBody tests if the `in(body)` param is declared, e.g. for the middleware deciding whether to read the body.
+func (paramsAPI *ParamsAPI) HasBody() bool {
+	return paramsAPI.hasBody
+}
+
+// HasFormData tests if the `in(formData)` param is declared, including the file and `fallback` ones.
+func (paramsAPI *ParamsAPI) HasFormData() bool {
+	return paramsAPI.hasFormData
+}
+
+// HasFile tests if the file param is declared.
+func (paramsAPI *ParamsAPI) HasFile() bool {
+	return paramsAPI.hasFile
+}
+
 // MaxMemory gets maxMemory
 // when request Content-Type is multipart/form-data, the max memory for body.
 func (paramsAPI *ParamsAPI) MaxMemory() int64 {
@@ -314,27 +1091,95 @@ func (paramsAPI *ParamsAPI) SetMaxMemory(maxMemory int64) {
 	paramsAPI.maxMemory = maxMemory
 }
 
+// MaxBody gets the max size of the `body` param.
+func (paramsAPI *ParamsAPI) MaxBody() int64 {
+	return paramsAPI.maxBody
+}
+
+// SetMaxBody sets the max size of the `body` param.
+func (paramsAPI *ParamsAPI) SetMaxBody(maxBody int64) {
+	paramsAPI.maxBody = maxBody
+}
+
+// SetJSONSchemaValidateFunc sets the validator used by `ValidateJSONSchema`.
+func (paramsAPI *ParamsAPI) SetJSONSchemaValidateFunc(fn JSONSchemaValidateFunc) {
+	paramsAPI.jsonSchemaValidateFunc = fn
+}
+
+// ValidateJSONSchema validates the request body against an external JSON Schema,
+// in addition to the field tags.
+// note: the validator must be set by `SetJSONSchemaValidateFunc` first.
+func (paramsAPI *ParamsAPI) ValidateJSONSchema(schema []byte, body []byte) error {
+	if paramsAPI.jsonSchemaValidateFunc == nil {
+		return NewError(paramsAPI.name, "body", "JSON Schema validator is not set")
+	}
+	if err := paramsAPI.jsonSchemaValidateFunc(schema, body); err != nil {
+		return newBindError(paramsAPI.name, "body", err.Error())
+	}
+	return nil
+}
+
 // NewReceiver creates a new struct pointer and the field's values  for its receive parameterste it.
 func (paramsAPI *ParamsAPI) NewReceiver() (interface{}, []reflect.Value) {
 	object := reflect.New(paramsAPI.structType)
 	return object.Interface(), paramsAPI.fieldsForBinding(object.Elem())
 }
 
+// cacheFieldNodes collects the embedded structs on the params' field paths, parents first,
+// so that each of them is resolved once per receiver, instead of walking the path of every param.
+func (m *ParamsAPI) cacheFieldNodes() {
+	nodes := make(map[string]int)
+	for _, param := range m.params {
+		parent := -1
+		for depth := 1; depth < len(param.indexPath); depth++ {
+			key := fmt.Sprint(param.indexPath[:depth])
+			node, ok := nodes[key]
+			if !ok {
+				node = len(m.fieldNodes)
+				m.fieldNodes = append(m.fieldNodes, fieldNode{parent: parent, index: param.indexPath[depth-1]})
+				nodes[key] = node
+			}
+			parent = node
+		}
+		param.node = parent
+	}
+}
+
 func (paramsAPI *ParamsAPI) fieldsForBinding(structElem reflect.Value) []reflect.Value {
-	count := len(paramsAPI.params)
-	fields := make([]reflect.Value, count)
-	for i := 0; i < count; i++ {
+	// the few embedded structs are kept on the stack
+	var buf [8]reflect.Value
+	nodes := buf[:0]
+	if len(paramsAPI.fieldNodes) > len(buf) {
+		nodes = make([]reflect.Value, 0, len(paramsAPI.fieldNodes))
+	}
+	for _, node := range paramsAPI.fieldNodes {
 		value := structElem
-		param := paramsAPI.params[i]
-		for _, index := range param.indexPath {
-			value = value.Field(index)
+		if node.parent >= 0 {
+			value = nodes[node.parent]
+		}
+		value = value.Field(node.index)
+		if value.Kind() == reflect.Ptr {
+			// the embedded struct pointer
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
 		}
-		fields[i] = value
+		nodes = append(nodes, value)
+	}
+	fields := make([]reflect.Value, len(paramsAPI.params))
+	for i, param := range paramsAPI.params {
+		value := structElem
+		if param.node >= 0 {
+			value = nodes[param.node]
+		}
+		fields[i] = value.Field(param.indexPath[len(param.indexPath)-1])
 	}
 	return fields
 }
 
-// BindByName binds the net/http request params to a new struct and validate it.
+// BindByName binds the net/http request params to a new struct and validate it,
+// the returned value is the new struct pointer, e.g. `*MyParams`, so no reflection is needed.
 func BindByName(
 	paramsAPIName string,
 	req *http.Request,
@@ -364,6 +1209,112 @@ func Bind(
 	return paramsAPI.BindAt(structPointer, req, pathParams)
 }
 
+// BindMulti binds the net/http request params to several struct pointers and validate them,
+// e.g. the paging, the filters and the body, the errors of each struct are aggregated into a `MultiError`.
+// note: the request body can only be read once, so at most one of the structs can declare the `in(body)` param.
+func BindMulti(
+	req *http.Request,
+	pathParams KV,
+	structPointers ...interface{},
+) error {
+	paramsAPIs := make([]*ParamsAPI, len(structPointers))
+	var hasBody bool
+	for i, structPointer := range structPointers {
+		paramsAPI, err := GetParamsAPI(reflect.TypeOf(structPointer).String())
+		if err != nil {
+			return err
+		}
+		if paramsAPI.HasBody() {
+			if hasBody {
+				return NewError(paramsAPI.name, "body", "more than one struct declares the `in(body)` param, the body can only be read once")
+			}
+			hasBody = true
+		}
+		paramsAPIs[i] = paramsAPI
+	}
+	var errs MultiError
+	for i, paramsAPI := range paramsAPIs {
+		if err := paramsAPI.BindAt(structPointers[i], req, pathParams); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// BindBatch decodes the net/http request's JSON array body into the items of the registered struct type,
+// and validates each item by its param tags, regardless of the positions of the params,
+// a required param must not be the zero value.
+// The items are the new struct pointers in order, and the errors point out the index, e.g. `[1].name too short`.
+func BindBatch(itemStructPointer interface{}, req *http.Request) ([]reflect.Value, MultiError) {
+	paramsAPI, err := GetParamsAPI(reflect.TypeOf(itemStructPointer).String())
+	if err != nil {
+		return nil, MultiError{err}
+	}
+	return paramsAPI.BindBatch(req)
+}
+
+// BindBatch decodes the net/http request's JSON array body into the items and validates each of them,
+// see the package function `BindBatch`.
+func (paramsAPI *ParamsAPI) BindBatch(req *http.Request) ([]reflect.Value, MultiError) {
+	if req.Body == nil {
+		return nil, MultiError{newBindError(paramsAPI.name, "body", "missing body")}
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, paramsAPI.maxBody+1))
+	req.Body.Close()
+	if err != nil {
+		return nil, MultiError{newBindError(paramsAPI.name, "body", err.Error())}
+	}
+	if int64(len(body)) > paramsAPI.maxBody {
+		return nil, MultiError{newBindError(paramsAPI.name, "body", "body too large")}
+	}
+	var raws []json.RawMessage
+	if err = json.Unmarshal(body, &raws); err != nil {
+		return nil, MultiError{newBindError(paramsAPI.name, "body", err.Error())}
+	}
+	items := make([]reflect.Value, len(raws))
+	var errs MultiError
+	for i, raw := range raws {
+		items[i] = reflect.New(paramsAPI.structType)
+		if err = json.Unmarshal(raw, items[i].Interface()); err == nil {
+			err = paramsAPI.validateItem(paramsAPI.fieldsForBinding(items[i].Elem()))
+		}
+		if err != nil {
+			errs = append(errs, indexError(paramsAPI.name, i, err))
+		}
+	}
+	return items, errs
+}
+
+// validateItem validates the fields of a batch item.
+func (paramsAPI *ParamsAPI) validateItem(fields []reflect.Value) error {
+	for i, param := range paramsAPI.params {
+		if param.isRequired && fields[i].IsZero() {
+			return param.myError("missing param")
+		}
+		if err := param.validate(fields[i]); err != nil {
+			return err
+		}
+	}
+	return paramsAPI.validateFields(fields)
+}
+
+// indexError points out the index of the failed batch item.
+func indexError(api string, i int, err error) error {
+	index := fmt.Sprintf("[%d]", i)
+	switch e := err.(type) {
+	case *ValidationError:
+		e.field = index + "." + e.field
+		return e
+	case *Error:
+		e.Param = index + "." + e.Param
+		return e
+	}
+	return newBindError(api, index, err.Error())
+}
+
 // BindAt binds the net/http request params to a struct pointer and validate it.
 // note: structPointer must be struct pointer.
 func (paramsAPI *ParamsAPI) BindAt(
@@ -373,7 +1324,7 @@ func (paramsAPI *ParamsAPI) BindAt(
 ) error {
 	name := reflect.TypeOf(structPointer).String()
 	if name != paramsAPI.name {
-		return errors.New("the structPointer's type `" + name + "` does not match type `" + paramsAPI.name + "`")
+		return NewError(paramsAPI.name, "*", "the structPointer's type `"+name+"` does not match")
 	}
 	return paramsAPI.BindFields(
 		paramsAPI.fieldsForBinding(reflect.ValueOf(structPointer).Elem()),
@@ -382,33 +1333,138 @@ func (paramsAPI *ParamsAPI) BindAt(
 	)
 }
 
-// BindNew binds the net/http request params to a struct pointer and validate it.
-func (paramsAPI *ParamsAPI) BindNew(
+// BindHeaderOnly binds the net/http request's path, header, cookie, host, scheme and auth params to a struct pointer and validate it,
+// it never parses the form or reads the body, so that the request body stays untouched.
+// note: structPointer must be struct pointer, and it can not declare `query`, `formData` or `body` params.
+func (paramsAPI *ParamsAPI) BindHeaderOnly(
+	structPointer interface{},
+	req *http.Request,
+	pathParams KV,
+) error {
+	for _, param := range paramsAPI.params {
+		switch param.In() {
+		case "path", "header", "cookie", "host", "scheme", "auth":
+		default:
+			return NewError(paramsAPI.name, param.name, "`in("+param.In()+")` param can not be bound by BindHeaderOnly")
+		}
+	}
+	name := reflect.TypeOf(structPointer).String()
+	if name != paramsAPI.name {
+		return NewError(paramsAPI.name, "*", "the structPointer's type `"+name+"` does not match")
+	}
+	return paramsAPI.BindFieldsWithOptions(
+		paramsAPI.fieldsForBinding(reflect.ValueOf(structPointer).Elem()),
+		req,
+		pathParams,
+		BindOptions{headerOnly: true},
+	)
+}
+
+// BindNew binds the net/http request params to a struct pointer and validate it.
+func (paramsAPI *ParamsAPI) BindNew(
+	req *http.Request,
+	pathParams KV,
+) (
+	interface{},
+	error,
+) {
+	structPrinter, fields := paramsAPI.NewReceiver()
+	err := paramsAPI.BindFields(fields, req, pathParams)
+	return structPrinter, err
+}
+
+// BindPooled is like BindNew, but the struct pointer is taken from a pool of the ParamsAPI,
+// and should be returned by `Release` after use, to cut the allocations of the short-lived request structs.
+// note: the struct is reset and reused after `Release`, so it and its slices, maps and pointers
+// must not be retained or referenced after `Release`, copy them if needed.
+func (paramsAPI *ParamsAPI) BindPooled(
+	req *http.Request,
+	pathParams KV,
+) (
+	interface{},
+	error,
+) {
+	structPointer, fields := paramsAPI.pooledReceiver()
+	err := paramsAPI.BindFields(fields, req, pathParams)
+	return structPointer, err
+}
+
+// Release resets the struct pointer from `BindPooled` or `FasthttpBindPooled`, and returns it to the pool,
+// the struct pointer of another type is ignored.
+func (paramsAPI *ParamsAPI) Release(structPointer interface{}) {
+	v := reflect.ValueOf(structPointer)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Type().Elem() != paramsAPI.structType {
+		return
+	}
+	v.Elem().Set(reflect.Zero(paramsAPI.structType))
+	paramsAPI.pool.Put(structPointer)
+}
+
+// pooledReceiver is like NewReceiver, but the struct pointer is taken from the pool.
+func (paramsAPI *ParamsAPI) pooledReceiver() (interface{}, []reflect.Value) {
+	structPointer := paramsAPI.pool.Get()
+	if structPointer == nil {
+		return paramsAPI.NewReceiver()
+	}
+	return structPointer, paramsAPI.fieldsForBinding(reflect.ValueOf(structPointer).Elem())
+}
+
+// RawBind binds the net/http request params to the original struct pointer and validate it.
+func (paramsAPI *ParamsAPI) RawBind(
 	req *http.Request,
 	pathParams KV,
 ) (
 	interface{},
 	error,
 ) {
-	structPrinter, fields := paramsAPI.NewReceiver()
+	fields := paramsAPI.fieldsForBinding(reflect.ValueOf(paramsAPI.rawStructPointer).Elem())
 	err := paramsAPI.BindFields(fields, req, pathParams)
-	return structPrinter, err
+	return paramsAPI.rawStructPointer, err
 }
 
-// RawBind binds the net/http request params to the original struct pointer and validate it.
-func (paramsAPI *ParamsAPI) RawBind(
+// BindNewContext is like BindNew, but the binding is canceled when ctx is done.
+func (paramsAPI *ParamsAPI) BindNewContext(
+	ctx context.Context,
 	req *http.Request,
 	pathParams KV,
 ) (
 	interface{},
 	error,
 ) {
-	var fields []reflect.Value
-	for _, param := range paramsAPI.params {
-		fields = append(fields, param.rawValue)
+	structPrinter, fields := paramsAPI.NewReceiver()
+	err := paramsAPI.BindFieldsContext(ctx, fields, req, pathParams)
+	return structPrinter, err
+}
+
+// BindFieldsContext is like BindFields, but the binding is canceled when ctx is done,
+// e.g. the slow client is disconnected while its body is read.
+// ctx is also passed to the converters registered by `RegisterConverterContext`
+// and the body decoder of the `BodyDecodeContext` option.
+func (paramsAPI *ParamsAPI) BindFieldsContext(
+	ctx context.Context,
+	fields []reflect.Value,
+	req *http.Request,
+	pathParams KV,
+) error {
+	if err := ctx.Err(); err != nil {
+		return newBindError(paramsAPI.name, "?", err.Error())
 	}
-	err := paramsAPI.BindFields(fields, req, pathParams)
-	return paramsAPI.rawStructPointer, err
+	r := req.WithContext(ctx)
+	if req.Body != nil {
+		r.Body = &contextReader{ctx: ctx, ReadCloser: req.Body}
+	}
+	err := paramsAPI.BindFields(fields, r, pathParams)
+	// keep the form parsed on the shallow copy in req, like BindFields
+	req.Form, req.PostForm, req.MultipartForm = r.Form, r.PostForm, r.MultipartForm
+	return err
+}
+
+// BindOptions overrides the registered settings of the ParamsAPI for a single binding.
+type BindOptions struct {
+	// MaxMemory is the max memory for the multipart/form-data body, the registered one is used if it is 0.
+	MaxMemory int64
+	// headerOnly skips parsing the form, it is set by BindHeaderOnly.
+	headerOnly bool
 }
 
 // BindFields binds the net/http request params to a struct and validate it.
@@ -417,16 +1473,50 @@ func (paramsAPI *ParamsAPI) BindFields(
 	fields []reflect.Value,
 	req *http.Request,
 	pathParams KV,
+) error {
+	return paramsAPI.BindFieldsWithOptions(fields, req, pathParams, BindOptions{})
+}
+
+// BindFieldsWithOptions is like BindFields, but the options override the registered settings for this request,
+// e.g. the same struct is used behind the endpoints with different upload limits.
+func (paramsAPI *ParamsAPI) BindFieldsWithOptions(
+	fields []reflect.Value,
+	req *http.Request,
+	pathParams KV,
+	opts BindOptions,
 ) (
 	err error,
 ) {
+	var maxMemory = paramsAPI.maxMemory
+	if opts.MaxMemory > 0 {
+		maxMemory = opts.MaxMemory
+	}
+	// passed to the converters and the body decoder, it is ctx of `BindFieldsContext`
+	var ctx = req.Context()
 	if pathParams == nil {
 		pathParams = Map(map[string]string{})
 	}
-	if req.Form == nil {
-		req.ParseMultipartForm(paramsAPI.maxMemory)
+	if paramsAPI.strictPathParams {
+		if err = paramsAPI.checkPathParams(pathParams); err != nil {
+			return err
+		}
 	}
+	var method = req.Method
 	var queryValues url.Values
+	var streamed map[string]bool
+	var counter *countingReader
+	if paramsAPI.countBody && req.Body != nil {
+		body := req.Body
+		counter = &countingReader{ReadCloser: body}
+		req.Body = counter
+		// the caller's body is put back after binding
+		defer func() { req.Body = body }()
+	}
+	// the form is parsed up front, so that req.Form and req.MultipartForm are available to the handler after binding,
+	// except for the streamed files, which are read part by part from the body.
+	if req.Form == nil && !opts.headerOnly && !paramsAPI.streamFiles {
+		req.ParseMultipartForm(maxMemory)
+	}
 	defer func() {
 		if p := recover(); p != nil {
 			err = NewError(paramsAPI.name, "?", fmt.Sprint(p))
@@ -435,18 +1525,80 @@ func (paramsAPI *ParamsAPI) BindFields(
 
 	for i, param := range paramsAPI.params {
 		value := fields[i]
+		if param.readonly {
+			if param.supplied(func(source paramSource) ([]string, bool) {
+				return httpSourceValues(req, pathParams, source, maxMemory)
+			}) {
+				return param.myError("read-only param can not be supplied")
+			}
+			continue
+		}
+		if len(param.sources) > 0 {
+			paramValues, ok := param.lookupSources(func(source paramSource) ([]string, bool) {
+				return httpSourceValues(req, pathParams, source, maxMemory)
+			})
+			if ok {
+				if err = param.assign(ctx, value, paramValues); err != nil {
+					return param.myError(err.Error())
+				}
+			} else if param.requiredFor(method) {
+				return param.myError("missing param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
+			}
+			if err = param.validate(value); err != nil {
+				return err
+			}
+			continue
+		}
 		switch param.In() {
 		case "path":
+			if value.Type() == stringMapType {
+				// the catch-all path param
+				kv, ok := pathParams.(KeysKV)
+				if !ok {
+					return param.myError("the path params can not be listed, the KV must implement KeysKV")
+				}
+				if len(kv.Keys()) == 0 {
+					return param.myError("missing path param")
+				}
+				value.Set(reflect.ValueOf(kvToMap(kv)))
+				break
+			}
 			paramValue, ok := pathParams.Get(param.name)
 			if !ok {
 				return param.myError("missing path param")
 			}
 			// fmt.Printf("paramName:%s\nvalue:%#v\n\n", param.name, paramValue)
-			if err = convertAssign(value, []string{paramValue}); err != nil {
+			if err = param.assign(ctx, value, []string{paramValue}); err != nil {
 				return param.myError(err.Error())
 			}
 
 		case "query":
+			if value.Type() == urlValuesType {
+				if queryValues == nil {
+					queryValues, err = url.ParseQuery(req.URL.RawQuery)
+					if err != nil {
+						queryValues = make(url.Values)
+					}
+				}
+				if len(queryValues) > 0 {
+					value.Set(reflect.ValueOf(copyValues(queryValues)))
+				} else if param.requiredFor(method) {
+					return param.myError("missing query param")
+				}
+				break
+			}
+			if isKeyValuesType(value.Type()) {
+				// keep the order in which the bracketed params appear in the query string
+				kvs := bracketKeyValues(parseOrderedQuery(req.URL.RawQuery), param.name)
+				if len(kvs) > 0 {
+					setKeyValues(value, kvs)
+				} else if param.requiredFor(method) {
+					return param.myError("missing query param")
+				}
+				break
+			}
 			if queryValues == nil {
 				queryValues, err = url.ParseQuery(req.URL.RawQuery)
 				if err != nil {
@@ -454,62 +1606,167 @@ func (paramsAPI *ParamsAPI) BindFields(
 				}
 			}
 			paramValues, ok := queryValues[param.name]
-			if ok {
-				if err = convertAssign(value, paramValues); err != nil {
+			if !ok && isIndexableSlice(value.Type()) {
+				if indexed := bracketIndexes(queryValues, param.name); len(indexed) > 0 {
+					if err = paramsAPI.assignIndexed(ctx, param, value, indexed); err != nil {
+						return param.myError(err.Error())
+					}
+					break
+				}
+			}
+			if ok && !param.noValueDefaults(req.URL.RawQuery) {
+				if paramValues, err = paramsAPI.decodeCharset(paramValues); err != nil {
+					return param.myError(err.Error())
+				}
+				if err = param.assign(ctx, value, paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+			} else if !ok && param.requiredFor(method) {
 				return param.myError("missing query param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "formData":
 			// Can not exist with `body` param at the same time
+			if req.Form == nil {
+				if paramsAPI.streamFiles {
+					if streamed, err = paramsAPI.streamMultipart(req, fields, maxMemory); err != nil {
+						return err
+					}
+				} else {
+					req.ParseMultipartForm(maxMemory)
+				}
+			}
+			if value.Type() == fileHandlerType {
+				ok := streamed[param.name]
+				if streamed == nil && req.MultipartForm != nil {
+					// the form is parsed before streaming, e.g. by a `fallback` param
+					if ok, err = param.handleFiles(value, req.MultipartForm.File[param.name]); err != nil {
+						return err
+					}
+				}
+				if !ok && param.requiredFor(method) {
+					return param.myError("missing formData param")
+				}
+				continue
+			}
+			if param.IsFile() && value.Kind() == reflect.Map {
+				ok, err := param.assignFileMap(value, req.MultipartForm)
+				if err != nil {
+					return err
+				}
+				if !ok && param.requiredFor(method) {
+					return param.myError("missing formData param")
+				}
+				continue
+			}
+			if param.IsFile() && value.Kind() == reflect.Slice {
+				var fhs []*multipart.FileHeader
+				if req.MultipartForm != nil {
+					fhs = req.MultipartForm.File[param.name]
+				}
+				if len(fhs) == 0 {
+					if param.requiredFor(method) {
+						return param.myError("missing formData param")
+					}
+					continue
+				}
+				if err = param.assignFiles(value, fhs); err != nil {
+					return err
+				}
+				continue
+			}
 			if param.IsFile() {
 				if req.MultipartForm != nil {
 					fhs := req.MultipartForm.File[param.name]
 					if len(fhs) == 0 {
-						if param.IsRequired() {
+						if param.requiredFor(method) {
 							return param.myError("missing formData param")
 						}
 						continue
 					}
 					value.Set(reflect.ValueOf(fhs[0]).Elem())
-				} else if param.IsRequired() {
+					if err = param.validateFile(fhs[0]); err != nil {
+						return err
+					}
+				} else if param.requiredFor(method) {
 					return param.myError("missing formData param")
 				}
 				continue
 			}
 
 			paramValues, ok := req.PostForm[param.name]
+			if !ok && isIndexableSlice(value.Type()) {
+				if indexed := bracketIndexes(req.PostForm, param.name); len(indexed) > 0 {
+					if err = paramsAPI.assignIndexed(ctx, param, value, indexed); err != nil {
+						return param.myError(err.Error())
+					}
+					break
+				}
+			}
 			if ok {
-				if err = convertAssign(value, paramValues); err != nil {
+				if paramValues, err = paramsAPI.decodeCharset(paramValues); err != nil {
+					return param.myError(err.Error())
+				}
+				if err = param.assign(ctx, value, paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing formData param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "body":
 			// Theoretically there should be at most one `body` param, and can not exist with `formData` at the same time
+			if value.Type() == readerType {
+				// stream the body, it is neither read nor closed here
+				if req.Body != nil {
+					value.Set(reflect.ValueOf(req.Body))
+				} else if param.requiredFor(method) {
+					return param.myError("missing body param")
+				}
+				break
+			}
 			var body []byte
-			body, err = ioutil.ReadAll(req.Body)
+			body, err = ioutil.ReadAll(io.LimitReader(req.Body, paramsAPI.maxBody+1))
 			req.Body.Close()
-			if err == nil {
-				if err = paramsAPI.bodyDecodeFunc(value, body); err != nil {
+			if int64(len(body)) > paramsAPI.maxBody {
+				return param.myError("body too large")
+			}
+			if err == nil && len(body) == 0 && value.Kind() == reflect.Ptr {
+				// the absent optional struct pointer body stays nil
+				if param.requiredFor(method) {
+					return param.myError("missing body param")
+				}
+			} else if err == nil {
+				if err = checkJSONDepth(body, param.maxDepth); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+				if err = paramsAPI.decodeBody(ctx, req.Header.Get("Content-Type"), value, body); err != nil {
+					return param.myError(err.Error())
+				}
+			} else if ctxErr := req.Context().Err(); ctxErr != nil {
+				// canceled by `BindFieldsContext`
+				return param.myError(ctxErr.Error())
+			} else if param.requiredFor(method) {
 				return param.myError("missing body param")
 			}
 
 		case "header":
 			paramValues, ok := req.Header[param.name]
+			if ok && isETagHeader(param.name) {
+				paramValues = unquoteETags(paramValues)
+			}
 			if ok {
-				if err = convertAssign(value, paramValues); err != nil {
+				if err = param.assign(ctx, value, paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing header param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "cookie":
@@ -519,19 +1776,70 @@ func (paramsAPI *ParamsAPI) BindFields(
 				case cookieTypeString:
 					value.Set(reflect.ValueOf(c).Elem())
 				default:
-					if err = convertAssign(value, []string{c.Value}); err != nil {
+					if err = param.assign(ctx, value, []string{c.Value}); err != nil {
 						return param.myError(err.Error())
 					}
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing cookie param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "host":
+			if err = param.assign(ctx, value, []string{req.Host}); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "scheme":
+			if err = param.assign(ctx, value, []string{httpScheme(req)}); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "auth":
+			if s := req.Header.Get("Authorization"); len(s) > 0 {
+				auth, err := parseAuthorization(s)
+				if err != nil {
+					return param.myError(err.Error())
+				}
+				value.Set(reflect.ValueOf(auth))
+			} else if param.requiredFor(method) {
+				return param.myError("missing auth param")
 			}
+
+		case "bodysize":
+			// assigned after all the params read the body
+			continue
 		}
 		if err = param.validate(value); err != nil {
 			return err
 		}
 	}
-	return
+	if counter != nil {
+		for i, param := range paramsAPI.params {
+			if param.In() != "bodysize" {
+				continue
+			}
+			if err = param.assign(ctx, fields[i], []string{strconv.FormatInt(counter.n, 10)}); err != nil {
+				return param.myError(err.Error())
+			}
+			if err = param.validate(fields[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return paramsAPI.validateFields(fields)
+}
+
+// httpScheme returns the request scheme, considering the `X-Forwarded-Proto` header.
+func httpScheme(req *http.Request) string {
+	if proto := req.Header.Get("X-Forwarded-Proto"); len(proto) > 0 {
+		return strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
 }
 
 // FasthttpBindByName binds the net/http request params to a new struct and validate it.
@@ -573,7 +1881,7 @@ func (paramsAPI *ParamsAPI) FasthttpBindAt(
 ) error {
 	name := reflect.TypeOf(structPointer).String()
 	if name != paramsAPI.name {
-		return errors.New("the structPointer's type `" + name + "` does not match type `" + paramsAPI.name + "`")
+		return NewError(paramsAPI.name, "*", "the structPointer's type `"+name+"` does not match")
 	}
 	return paramsAPI.FasthttpBindFields(
 		paramsAPI.fieldsForBinding(reflect.ValueOf(structPointer).Elem()),
@@ -595,6 +1903,20 @@ func (paramsAPI *ParamsAPI) FasthttpBindNew(
 	return structPrinter, err
 }
 
+// FasthttpBindPooled is like FasthttpBindNew, but the struct pointer is taken from a pool of the ParamsAPI,
+// and should be returned by `Release` after use, see `BindPooled` for the aliasing hazards.
+func (paramsAPI *ParamsAPI) FasthttpBindPooled(
+	req *fasthttp.RequestCtx,
+	pathParams KV,
+) (
+	interface{},
+	error,
+) {
+	structPointer, fields := paramsAPI.pooledReceiver()
+	err := paramsAPI.FasthttpBindFields(fields, req, pathParams)
+	return structPointer, err
+}
+
 // RawBind binds the net/http request params to the original struct pointer and validate it.
 func (paramsAPI *ParamsAPI) FasthttpRawBind(
 	req *fasthttp.RequestCtx,
@@ -603,10 +1925,7 @@ func (paramsAPI *ParamsAPI) FasthttpRawBind(
 	interface{},
 	error,
 ) {
-	var fields []reflect.Value
-	for _, param := range paramsAPI.params {
-		fields = append(fields, param.rawValue)
-	}
+	fields := paramsAPI.fieldsForBinding(reflect.ValueOf(paramsAPI.rawStructPointer).Elem())
 	err := paramsAPI.FasthttpBindFields(fields, req, pathParams)
 	return paramsAPI.rawStructPointer, err
 }
@@ -620,9 +1939,16 @@ func (paramsAPI *ParamsAPI) FasthttpBindFields(
 ) (
 	err error,
 ) {
+	// passed to the converters and the body decoder
+	var ctx = context.Background()
 	if pathParams == nil {
 		pathParams = Map(map[string]string{})
 	}
+	if paramsAPI.strictPathParams {
+		if err = paramsAPI.checkPathParams(pathParams); err != nil {
+			return err
+		}
+	}
 
 	defer func() {
 		if p := recover(); p != nil {
@@ -630,73 +1956,241 @@ func (paramsAPI *ParamsAPI) FasthttpBindFields(
 		}
 	}()
 
+	var method = string(req.Method())
 	var formValues = fasthttpFormValues(req)
 	for i, param := range paramsAPI.params {
 		value := fields[i]
+		if param.readonly {
+			if param.supplied(func(source paramSource) ([]string, bool) {
+				return fasthttpSourceValues(req, pathParams, formValues, source)
+			}) {
+				return param.myError("read-only param can not be supplied")
+			}
+			continue
+		}
+		if len(param.sources) > 0 {
+			paramValues, ok := param.lookupSources(func(source paramSource) ([]string, bool) {
+				return fasthttpSourceValues(req, pathParams, formValues, source)
+			})
+			if ok {
+				if err = param.assign(ctx, value, paramValues); err != nil {
+					return param.myError(err.Error())
+				}
+			} else if param.requiredFor(method) {
+				return param.myError("missing param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
+			}
+			if err = param.validate(value); err != nil {
+				return err
+			}
+			continue
+		}
 		switch param.In() {
 		case "path":
+			if value.Type() == stringMapType {
+				// the catch-all path param
+				kv, ok := pathParams.(KeysKV)
+				if !ok {
+					return param.myError("the path params can not be listed, the KV must implement KeysKV")
+				}
+				if len(kv.Keys()) == 0 {
+					return param.myError("missing path param")
+				}
+				value.Set(reflect.ValueOf(kvToMap(kv)))
+				break
+			}
 			paramValue, ok := pathParams.Get(param.name)
 			if !ok {
 				return param.myError("missing path param")
 			}
 			// fmt.Printf("paramName:%s\nvalue:%#v\n\n", param.name, paramValue)
-			if err = convertAssign(value, []string{paramValue}); err != nil {
+			if err = param.assign(ctx, value, []string{paramValue}); err != nil {
 				return param.myError(err.Error())
 			}
 
 		case "query":
+			if value.Type() == urlValuesType {
+				all := make(url.Values)
+				req.QueryArgs().VisitAll(func(k, v []byte) {
+					all.Add(string(k), string(v))
+				})
+				if len(all) > 0 {
+					value.Set(reflect.ValueOf(all))
+				} else if param.requiredFor(method) {
+					return param.myError("missing query param")
+				}
+				break
+			}
+			if isKeyValuesType(value.Type()) {
+				var kvs []KeyValue
+				req.QueryArgs().VisitAll(func(k, v []byte) {
+					kvs = append(kvs, KeyValue{Key: string(k), Value: string(v)})
+				})
+				if kvs = bracketKeyValues(kvs, param.name); len(kvs) > 0 {
+					setKeyValues(value, kvs)
+				} else if param.requiredFor(method) {
+					return param.myError("missing query param")
+				}
+				break
+			}
 			paramValuesBytes := req.QueryArgs().PeekMulti(param.name)
-			if len(paramValuesBytes) > 0 {
-				var paramValues = make([]string, len(paramValuesBytes))
-				for i, b := range paramValuesBytes {
-					paramValues[i] = string(b)
+			if _, ok := param.tags["presence"]; ok && len(paramValuesBytes) == 0 && req.QueryArgs().Has(param.name) {
+				// the no-value form `?flag`
+				paramValuesBytes = [][]byte{{}}
+			}
+			if len(paramValuesBytes) == 0 && isIndexableSlice(value.Type()) {
+				form := make(map[string][]string)
+				req.QueryArgs().VisitAll(func(k, v []byte) {
+					form[string(k)] = append(form[string(k)], string(v))
+				})
+				if indexed := bracketIndexes(form, param.name); len(indexed) > 0 {
+					if err = paramsAPI.assignIndexed(ctx, param, value, indexed); err != nil {
+						return param.myError(err.Error())
+					}
+					break
+				}
+			}
+			var paramValues = make([]string, len(paramValuesBytes))
+			for i, b := range paramValuesBytes {
+				paramValues[i] = string(b)
+			}
+			if len(paramValues) > 0 && !param.noValueDefaults(string(req.URI().QueryString())) {
+				if paramValues, err = paramsAPI.decodeCharset(paramValues); err != nil {
+					return param.myError(err.Error())
 				}
-				if err = convertAssign(value, paramValues); err != nil {
+				if err = param.assign(ctx, value, paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if len(paramValuesBytes) == 0 && param.IsRequired() {
+			} else if len(paramValuesBytes) == 0 && param.requiredFor(method) {
 				return param.myError("missing query param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "formData":
 			// Can not exist with `body` param at the same time
+			if value.Type() == fileHandlerType {
+				// the fasthttp multipart form is already parsed
+				var fhs []*multipart.FileHeader
+				if form, _ := req.MultipartForm(); form != nil {
+					fhs = form.File[param.name]
+				}
+				ok, err := param.handleFiles(value, fhs)
+				if err != nil {
+					return err
+				}
+				if !ok && param.requiredFor(method) {
+					return param.myError("missing formData param")
+				}
+				continue
+			}
+			if param.IsFile() && value.Kind() == reflect.Map {
+				form, _ := req.MultipartForm()
+				ok, err := param.assignFileMap(value, form)
+				if err != nil {
+					return err
+				}
+				if !ok && param.requiredFor(method) {
+					return param.myError("missing formData param")
+				}
+				continue
+			}
+			if param.IsFile() && value.Kind() == reflect.Slice {
+				var fhs []*multipart.FileHeader
+				if form, _ := req.MultipartForm(); form != nil {
+					fhs = form.File[param.name]
+				}
+				if len(fhs) == 0 {
+					if param.requiredFor(method) {
+						return param.myError("missing formData param")
+					}
+					continue
+				}
+				if err = param.assignFiles(value, fhs); err != nil {
+					return err
+				}
+				continue
+			}
 			if param.IsFile() {
 				if fh, err := req.FormFile(param.name); err == nil {
 					value.Set(reflect.ValueOf(fh).Elem())
-				} else if param.IsRequired() {
+					if err = param.validateFile(fh); err != nil {
+						return err
+					}
+				} else if param.requiredFor(method) {
 					return param.myError("missing formData param")
 				}
 				continue
 			}
 
 			paramValues, ok := formValues[param.name]
+			if !ok && isIndexableSlice(value.Type()) {
+				if indexed := bracketIndexes(formValues, param.name); len(indexed) > 0 {
+					if err = paramsAPI.assignIndexed(ctx, param, value, indexed); err != nil {
+						return param.myError(err.Error())
+					}
+					break
+				}
+			}
 			if ok {
-				if err = convertAssign(value, paramValues); err != nil {
+				if paramValues, err = paramsAPI.decodeCharset(paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+				if err = param.assign(ctx, value, paramValues); err != nil {
+					return param.myError(err.Error())
+				}
+			} else if param.requiredFor(method) {
 				return param.myError("missing formData param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "body":
 			// Theoretically there should be at most one `body` param, and can not exist with `formData` at the same time
 			body := req.PostBody()
-			if body != nil {
-				if err = paramsAPI.bodyDecodeFunc(value, body); err != nil {
+			if value.Type() == readerType {
+				// the fasthttp body is already in memory
+				if body != nil {
+					value.Set(reflect.ValueOf(bytes.NewReader(body)))
+				} else if param.requiredFor(method) {
+					return param.myError("missing body param")
+				}
+				break
+			}
+			if int64(len(body)) > paramsAPI.maxBody {
+				return param.myError("body too large")
+			}
+			if len(body) == 0 && value.Kind() == reflect.Ptr {
+				// the absent optional struct pointer body stays nil
+				if param.requiredFor(method) {
+					return param.myError("missing body param")
+				}
+			} else if body != nil {
+				if err = checkJSONDepth(body, param.maxDepth); err != nil {
+					return param.myError(err.Error())
+				}
+				if err = paramsAPI.decodeBody(ctx, string(req.Request.Header.ContentType()), value, body); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing body param")
 			}
 
 		case "header":
 			paramValueBytes := req.Request.Header.Peek(param.name)
 			if paramValueBytes != nil {
-				if err = convertAssign(value, []string{string(paramValueBytes)}); err != nil {
+				paramValues := []string{string(paramValueBytes)}
+				if isETagHeader(param.name) {
+					paramValues = unquoteETags(paramValues)
+				}
+				if err = param.assign(ctx, value, paramValues); err != nil {
 					return param.myError(err.Error())
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing header param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
 			}
 
 		case "cookie":
@@ -712,19 +2206,169 @@ func (paramsAPI *ParamsAPI) FasthttpBindFields(
 					value.Set(reflect.ValueOf(*c))
 
 				default:
-					if err = convertAssign(value, []string{string(bcookie)}); err != nil {
+					if err = param.assign(ctx, value, []string{string(bcookie)}); err != nil {
 						return param.myError(err.Error())
 					}
 				}
-			} else if param.IsRequired() {
+			} else if param.requiredFor(method) {
 				return param.myError("missing cookie param")
+			} else if err = param.assignDefault(ctx, value); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "host":
+			if err = param.assign(ctx, value, []string{string(req.Host())}); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "scheme":
+			if err = param.assign(ctx, value, []string{fasthttpScheme(req)}); err != nil {
+				return param.myError(err.Error())
+			}
+
+		case "auth":
+			if b := req.Request.Header.Peek("Authorization"); len(b) > 0 {
+				auth, err := parseAuthorization(string(b))
+				if err != nil {
+					return param.myError(err.Error())
+				}
+				value.Set(reflect.ValueOf(auth))
+			} else if param.requiredFor(method) {
+				return param.myError("missing auth param")
+			}
+
+		case "bodysize":
+			if err = param.assign(ctx, value, []string{strconv.Itoa(len(req.PostBody()))}); err != nil {
+				return param.myError(err.Error())
 			}
 		}
 		if err = param.validate(value); err != nil {
 			return err
 		}
 	}
-	return
+	return paramsAPI.validateFields(fields)
+}
+
+// fasthttpScheme returns the request scheme, considering the `X-Forwarded-Proto` header.
+func fasthttpScheme(req *fasthttp.RequestCtx) string {
+	if proto := req.Request.Header.Peek("X-Forwarded-Proto"); len(proto) > 0 {
+		return strings.ToLower(strings.TrimSpace(strings.Split(string(proto), ",")[0]))
+	}
+	if req.IsTLS() {
+		return "https"
+	}
+	return "http"
+}
+
+// httpSourceValues looks up the values of a fallback source from the net/http request.
+func httpSourceValues(req *http.Request, pathParams KV, source paramSource, maxMemory int64) ([]string, bool) {
+	switch source.in {
+	case "path":
+		if v, ok := pathParams.Get(source.name); ok {
+			return []string{v}, true
+		}
+	case "query":
+		v, ok := req.URL.Query()[source.name]
+		return v, ok
+	case "formData":
+		if req.Form == nil {
+			req.ParseMultipartForm(maxMemory)
+		}
+		v, ok := req.PostForm[source.name]
+		return v, ok
+	case "header":
+		v, ok := req.Header[textproto.CanonicalMIMEHeaderKey(source.name)]
+		return v, ok
+	case "cookie":
+		if c, _ := req.Cookie(source.name); c != nil {
+			return []string{c.Value}, true
+		}
+	}
+	return nil, false
+}
+
+// fasthttpSourceValues looks up the values of a fallback source from the fasthttp request.
+func fasthttpSourceValues(req *fasthttp.RequestCtx, pathParams KV, formValues map[string][]string, source paramSource) ([]string, bool) {
+	var b []byte
+	switch source.in {
+	case "path":
+		if v, ok := pathParams.Get(source.name); ok {
+			return []string{v}, true
+		}
+		return nil, false
+	case "query":
+		bs := req.QueryArgs().PeekMulti(source.name)
+		if len(bs) == 0 {
+			return nil, false
+		}
+		v := make([]string, len(bs))
+		for i, b := range bs {
+			v[i] = string(b)
+		}
+		return v, true
+	case "formData":
+		v, ok := formValues[source.name]
+		return v, ok
+	case "header":
+		b = req.Request.Header.Peek(source.name)
+	case "cookie":
+		b = req.Request.Header.Cookie(source.name)
+	}
+	if b == nil {
+		return nil, false
+	}
+	return []string{string(b)}, true
+}
+
+// streamMultipart reads the multipart form part by part, streams the files to the `FileHandler` params,
+// skips the other files, and stores the values, bounded by the maxMemory, into the req.PostForm and req.Form.
+// It returns the names of the handled file params.
+func (paramsAPI *ParamsAPI) streamMultipart(req *http.Request, fields []reflect.Value, maxMemory int64) (map[string]bool, error) {
+	streamed := make(map[string]bool)
+	mr, err := req.MultipartReader()
+	if err != nil {
+		// not a multipart form, e.g. `application/x-www-form-urlencoded`
+		req.ParseMultipartForm(maxMemory)
+		return streamed, nil
+	}
+	req.PostForm = make(url.Values)
+	if req.Form, err = url.ParseQuery(req.URL.RawQuery); err != nil {
+		req.Form = make(url.Values)
+	}
+	remaining := maxMemory
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return streamed, nil
+		}
+		if err != nil {
+			return nil, newBindError(paramsAPI.name, "formData", err.Error())
+		}
+		name := part.FormName()
+		if len(part.FileName()) == 0 {
+			b, err := ioutil.ReadAll(io.LimitReader(part, remaining+1))
+			if err != nil {
+				return nil, newBindError(paramsAPI.name, name, err.Error())
+			}
+			if remaining -= int64(len(b)); remaining < 0 {
+				return nil, newBindError(paramsAPI.name, name, "multipart form too large")
+			}
+			req.PostForm.Add(name, string(b))
+			req.Form.Add(name, string(b))
+			continue
+		}
+		for i, param := range paramsAPI.params {
+			if param.name != name || param.In() != "formData" || fields[i].Type() != fileHandlerType {
+				continue
+			}
+			fh := &multipart.FileHeader{Filename: part.FileName(), Header: part.Header}
+			if err = param.handleFile(fields[i], fh, part); err != nil {
+				return nil, err
+			}
+			streamed[name] = true
+			break
+		}
+	}
 }
 
 // fasthttpFormValues returns all post data values with their keys
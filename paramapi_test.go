@@ -1,10 +1,24 @@
 package apiware
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/valyala/fasthttp"
 )
 
 func TestParsetags(t *testing.T) {
@@ -41,6 +55,19 @@ func TestFieldIsZero(t *testing.T) {
 	}
 }
 
+func TestParsetagsWithParentheses(t *testing.T) {
+	m := ParseTags(`in(query),required_with(A,B),desc(x)`)
+	if x := m["required_with"]; x != "A,B" {
+		t.Fatal("wrong value", x)
+	}
+	if x := m["desc"]; x != "x" {
+		t.Fatal("wrong value", x)
+	}
+	if x := len(m); x != 3 {
+		t.Fatal("wrong len", x, m)
+	}
+}
+
 func TestFieldvalidate(t *testing.T) {
 	type Schema struct {
 		A string  `param:"in(path),len(3:6),name(p)" err:"This is a custom error!"`
@@ -106,6 +133,206 @@ func TestFieldvalidate(t *testing.T) {
 	}
 }
 
+func TestFieldValidateSliceRegexp(t *testing.T) {
+	type tagsParams struct {
+		Tags []string `param:"in(query)" regexp:"^[a-z]+$"`
+	}
+	m, err := NewParamsAPI(new(tagsParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?tags=go&tags=web", nil), nil); err != nil {
+		t.Fatal("should validate", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?tags=go&tags=Web2&tags=x", nil), nil)
+	if e, ok := err.(*ValidationError); !ok || e.Field() != "tags[1]" || e.Error() != "tags[1] not match" {
+		t.Fatal("should not validate", err)
+	}
+}
+
+func TestRegexpCompiledWhenRegistering(t *testing.T) {
+	type warmParams struct {
+		Code string `param:"in(query)" regexp:"^[0-9]{4}$"`
+	}
+	m, err := NewParamsAPI(new(warmParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.params[0].regexp == nil {
+		t.Fatal("should be compiled when registering")
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?code=1234", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?code=12", nil), nil); err == nil {
+		t.Fatal("should fail for the unmatched value")
+	}
+
+	type badRegexpParams struct {
+		Code string `param:"in(query)" regexp:"^[0-9"`
+	}
+	if _, err = NewParamsAPI(new(badRegexpParams), nil, nil); err == nil {
+		t.Fatal("should fail for the invalid regexp when registering")
+	}
+}
+
+func TestWarmUp(t *testing.T) {
+	type warmLevel int
+	levelType := reflect.TypeOf(warmLevel(0))
+	levels := func(high warmLevel) func(reflect.Value, []string) error {
+		return func(dst reflect.Value, raw []string) error {
+			if raw[0] != "high" {
+				return errors.New("unknown level " + raw[0])
+			}
+			dst.SetInt(int64(high))
+			return nil
+		}
+	}
+	RegisterConverter(levelType, levels(2))
+	defer RegisterConverter(levelType, nil)
+	type warmUpParams struct {
+		Level warmLevel `param:"in(query)"`
+		Page  *int      `param:"in(query)"`
+	}
+	if err := Register(new(warmUpParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	m, _ := GetParamsAPI(reflect.TypeOf(new(warmUpParams)).String())
+	for _, param := range m.params {
+		if param.assigner.Load() != nil {
+			t.Fatal("should not prepare when registering", param.name)
+		}
+	}
+	WarmUp()
+	prepared := make([]interface{}, len(m.params))
+	for i, param := range m.params {
+		if prepared[i] = param.assigner.Load(); prepared[i] == nil {
+			t.Fatal("should prepare when warming up", param.name)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		v, err := m.BindNew(httptest.NewRequest("GET", "/?level=high&page=2", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := v.(*warmUpParams); p.Level != 2 || *p.Page != 2 {
+			t.Fatal("wrong value", p.Level, *p.Page)
+		}
+	}
+	for i, param := range m.params {
+		if param.assigner.Load() != prepared[i] {
+			t.Fatal("should not prepare again when binding", param.name)
+		}
+	}
+
+	// the converter registered after warming up is resolved again
+	RegisterConverter(levelType, levels(3))
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?level=high", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*warmUpParams); p.Level != 3 {
+		t.Fatal("should use the new converter", p.Level)
+	}
+	if m.params[0].assigner.Load() == prepared[0] {
+		t.Fatal("should prepare again after the converters change")
+	}
+}
+
+func TestFieldValidateCoordinates(t *testing.T) {
+	type pointParams struct {
+		Lat float64 `param:"in(query),latitude"`
+		Lng float64 `param:"in(query),longitude"`
+	}
+	m, err := NewParamsAPI(new(pointParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lng := m.params[0], m.params[1]
+	for _, f := range []float64{-90, 0, 31.2304, 90} {
+		if err := lat.validate(reflect.ValueOf(f)); err != nil {
+			t.Fatal("should validate", f, err)
+		}
+	}
+	for _, f := range []float64{-90.0001, 91} {
+		if err := lat.validate(reflect.ValueOf(f)); err == nil || err.Error() != "lat is not a valid latitude" {
+			t.Fatal("should not validate", f, err)
+		}
+	}
+	for _, f := range []float64{-180, 121.4737, 180} {
+		if err := lng.validate(reflect.ValueOf(f)); err != nil {
+			t.Fatal("should validate", f, err)
+		}
+	}
+	for _, f := range []float64{-180.5, 181} {
+		if err := lng.validate(reflect.ValueOf(f)); err == nil || err.Error() != "lng is not a valid longitude" {
+			t.Fatal("should not validate", f, err)
+		}
+	}
+
+	type badParams struct {
+		Lat string `param:"in(query),latitude"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-number field")
+	}
+}
+
+func TestNoValidate(t *testing.T) {
+	type trustedParams struct {
+		Limit int    `param:"in(header),name(X-Limit),range(1:100),novalidate"`
+		Email string `param:"in(header),name(X-Email),nonzero,required_with(Limit),novalidate" regexp:"^.+@.+$"`
+		Page  int    `param:"in(query),range(1:100)"`
+	}
+	m, err := NewParamsAPI(new(trustedParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/?page=1", nil)
+	req.Header.Set("X-Limit", "1000")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal("should bind without validating", err)
+	}
+	if p := v.(*trustedParams); p.Limit != 1000 || p.Email != "" {
+		t.Fatal("wrong value", p)
+	}
+	req = httptest.NewRequest("GET", "/?page=1000", nil)
+	if _, err = m.BindNew(req, nil); err == nil || err.Error() != "page too big" {
+		t.Fatal("should still validate other params", err)
+	}
+}
+
+func TestFieldValidateBase64(t *testing.T) {
+	type encodedParams struct {
+		Std string `param:"in(query),base64"`
+		URL string `param:"in(query),name(url),base64url"`
+	}
+	m, err := NewParamsAPI(new(encodedParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	std, url := m.params[0], m.params[1]
+	for _, s := range []string{"", "YWJj", "YQ==", "YQ", "a+/b"} {
+		if err := std.validate(reflect.ValueOf(s)); err != nil {
+			t.Fatal("should validate", s, err)
+		}
+	}
+	for _, s := range []string{"YQ=", "YQ===", "Y", "a-_b", "ab c"} {
+		if err := std.validate(reflect.ValueOf(s)); err == nil || err.Error() != "std is not a valid base64" {
+			t.Fatal("should not validate", s, err)
+		}
+	}
+	for _, s := range []string{"a-_b", "YQ==", "YQ"} {
+		if err := url.validate(reflect.ValueOf(s)); err != nil {
+			t.Fatal("should validate", s, err)
+		}
+	}
+	if err := url.validate(reflect.ValueOf("a+/b")); err == nil || err.Error() != "url is not a valid base64url" {
+		t.Fatal("should not validate", err)
+	}
+}
+
 func TestFieldOmit(t *testing.T) {
 	type schema struct {
 		A string `param:"-"`
@@ -185,18 +412,3098 @@ func TestInterfaceNewParamsAPI(t *testing.T) {
 	}
 }
 
-func makeWhitespaceVisible(s string) string {
-	s = strings.Replace(s, "\t", "\\t", -1)
-	s = strings.Replace(s, "\r\n", "\\r\\n", -1)
-	s = strings.Replace(s, "\r", "\\r", -1)
-	s = strings.Replace(s, "\n", "\\n", -1)
-	return s
+type color string
+
+func TestOneofCaseInsensitive(t *testing.T) {
+	type oneofParams struct {
+		Color  color  `param:"in(query),oneof(Red|Green|Blue),ci"`
+		Canon  color  `param:"in(query),oneof(Red|Green|Blue),ci,canon"`
+		Strict string `param:"in(query),oneof(Red|Green|Blue)"`
+	}
+	m, err := NewParamsAPI(new(oneofParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?color=rED&canon=gReEn&strict=Blue", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	p := v.(*oneofParams)
+	if p.Color != "rED" {
+		t.Fatal("wrong value", p.Color)
+	}
+	if p.Canon != "Green" {
+		t.Fatal("should canonicalize", p.Canon)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?color=red&canon=red&strict=blue", nil), nil)
+	if err == nil || err.Error() != "strict not in allowed set" {
+		t.Fatal("should not validate", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?color=purple&canon=red&strict=Red", nil), nil)
+	if err == nil || err.Error() != "color not in allowed set" {
+		t.Fatal("should not validate", err)
+	}
+
+	type badCanon struct {
+		Color color `param:"in(query),canon"`
+	}
+	if _, err = NewParamsAPI(new(badCanon), nil, nil); err == nil {
+		t.Fatal("`canon` without `oneof` should fail")
+	}
 }
-func isZero(v reflect.Value) bool {
-	return v.Interface() == reflect.Zero(v.Type()).Interface()
+
+func TestOneofIntegers(t *testing.T) {
+	type oneofIntParams struct {
+		Status string `param:"in(query),oneof(active|inactive|pending)"`
+		Level  int    `param:"in(query),oneof(1|2|3)"`
+		Codes  []uint `param:"in(query),oneof(200|404)"`
+		Kind   int    `param:"in(query),oneof(0|1)" err:"kind must be 0 or 1"`
+	}
+	m, err := NewParamsAPI(new(oneofIntParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?status=pending&level=2&codes=200&codes=404", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?status=pending&level=4", nil), nil)
+	if err == nil || err.Error() != "level not in allowed set" {
+		t.Fatal("should not validate", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?status=pending&level=1&codes=200&codes=500", nil), nil)
+	if err == nil || err.Error() != "codes[1] not in allowed set" {
+		t.Fatal("should not validate", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?status=pending&level=1&kind=2", nil), nil)
+	if err == nil || err.Error() != "kind must be 0 or 1" {
+		t.Fatal("should use the custom error", err)
+	}
+
+	for _, bad := range []interface{}{
+		new(struct {
+			Level int `param:"in(query),oneof(1|two)"`
+		}),
+		new(struct {
+			Status string `param:"in(query),oneof(a||b)"`
+		}),
+		new(struct {
+			Ratio float64 `param:"in(query),oneof(0.5|1)"`
+		}),
+	} {
+		if _, err = NewParamsAPI(bad, nil, nil); err == nil {
+			t.Fatalf("%T: malformed `oneof` should fail", bad)
+		}
+	}
 }
 
-func toString(v reflect.Value) (string, bool) {
-	s, ok := v.Interface().(string)
-	return s, ok
+func TestOptionalNumber(t *testing.T) {
+	type optionalParams struct {
+		N     *int     `param:"in(query),range(0:10)"`
+		Ratio *float64 `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(optionalParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*optionalParams); p.N != nil || p.Ratio != nil {
+		t.Fatal("should stay nil when absent", p.N, p.Ratio)
+	}
+	v, err = m.BindNew(httptest.NewRequest("GET", "/?n=0&ratio=0", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*optionalParams); p.N == nil || *p.N != 0 || p.Ratio == nil || *p.Ratio != 0 {
+		t.Fatal("should point to zero", p.N, p.Ratio)
+	}
+	v, err = m.BindNew(httptest.NewRequest("GET", "/?n=5&ratio=0.5", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*optionalParams); p.N == nil || *p.N != 5 || p.Ratio == nil || *p.Ratio != 0.5 {
+		t.Fatal("wrong value", p.N, p.Ratio)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?n=11", nil), nil)
+	if err == nil {
+		t.Fatal("should not validate")
+	}
+
+	type badParams struct {
+		S *[]string `param:"in(query)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-base pointer")
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	type sanitizeParams struct {
+		Comment string   `param:"in(query),sanitize"`
+		Tags    []string `param:"in(query),sanitize"`
+		Raw     string   `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(sanitizeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const query = "/?comment=%3Cb%3Ehi%3C%2Fb%3E&tags=%3Ci%3Ea%3C%2Fi%3E&tags=b&raw=%3Cb%3Ehi%3C%2Fb%3E"
+	v, err := m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*sanitizeParams); p.Comment != "<b>hi</b>" {
+		t.Fatal("should be no-op by default", p.Comment)
+	}
+
+	stripTags := regexp.MustCompile(`<[^>]*>`)
+	SetSanitizer(func(s string) string { return stripTags.ReplaceAllString(s, "") })
+	defer SetSanitizer(nil)
+	v, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*sanitizeParams)
+	if p.Comment != "hi" || !reflect.DeepEqual(p.Tags, []string{"a", "b"}) {
+		t.Fatal("should sanitize", p.Comment, p.Tags)
+	}
+	if p.Raw != "<b>hi</b>" {
+		t.Fatal("should not sanitize without the tag", p.Raw)
+	}
+
+	type badParams struct {
+		N int `param:"in(query),sanitize"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-string field")
+	}
+}
+
+func TestTimeSlice(t *testing.T) {
+	type timeSliceParams struct {
+		Days []time.Time `param:"in(query),time(2006-01-02),after(2016-01-01),before(2017-01-01)"`
+	}
+	m, err := NewParamsAPI(new(timeSliceParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := m.params[0]; !d.after.Equal(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)) || !d.before.Equal(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("should parse the bounds when registering", d.after, d.before)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?days=2016-03-01&days=2016-06-15&days=2016-12-31", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*timeSliceParams); len(p.Days) != 3 || p.Days[1].Month() != time.June {
+		t.Fatal("wrong value", p.Days)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?days=2016-03-01&days=2016-13-01", nil), nil)
+	if e, ok := err.(*Error); !ok || !strings.Contains(e.Reason, "at index 1") {
+		t.Fatal("should point out the invalid date", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?days=2016-03-01&days=2017-01-01", nil), nil)
+	if err == nil || err.Error() != "days[1] too big" {
+		t.Fatal("should not validate", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?days=2016-01-01", nil), nil)
+	if err == nil || err.Error() != "days[0] too small" {
+		t.Fatal("should not validate", err)
+	}
+
+	type badParams struct {
+		Day time.Time `param:"in(query),time(2006-01-02),after(01/01/2016)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for the malformed bound")
+	}
+}
+
+func TestNumberBounds(t *testing.T) {
+	type boundParams struct {
+		Page  int     `param:"in(query),gt(0)"`
+		Size  int     `param:"in(query),min(1),max(100)"`
+		Ratio float64 `param:"in(query),gt(0),lt(1)" err:"ratio must be within (0,1)"`
+	}
+	m, err := NewParamsAPI(new(boundParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?page=1&size=100&ratio=0.5", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	for query, reason := range map[string]string{
+		"/?page=0&size=1&ratio=0.5":   "page must be > 0",
+		"/?page=1&size=0&ratio=0.5":   "size must be >= 1",
+		"/?page=1&size=101&ratio=0.5": "size must be <= 100",
+		"/?page=1&size=1&ratio=1":     "ratio must be within (0,1)",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err == nil || err.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+
+	type badParams struct {
+		Name string `param:"in(query),min(1)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-number field")
+	}
+	type badBound struct {
+		Page int `param:"in(query),min(one)"`
+	}
+	if _, err = NewParamsAPI(new(badBound), nil, nil); err == nil {
+		t.Fatal("should fail for non-number bound")
+	}
+}
+
+func TestDefault(t *testing.T) {
+	type defaultParams struct {
+		Page  int      `param:"in(query),default(1),min(1)"`
+		Sort  string   `param:"in(query),default(id)"`
+		Tags  []string `param:"in(query),default(a,b)"`
+		Limit []int    `param:"in(header),name(X-Limit),default(10,20)"`
+	}
+	m, err := NewParamsAPI(new(defaultParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*defaultParams)
+	if p.Page != 1 || p.Sort != "id" || !reflect.DeepEqual(p.Tags, []string{"a", "b"}) || !reflect.DeepEqual(p.Limit, []int{10, 20}) {
+		t.Fatalf("should assign the defaults: %#v", p)
+	}
+	v, err = m.BindNew(httptest.NewRequest("GET", "/?page=3&sort=&tags=c", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p = v.(*defaultParams)
+	if p.Page != 3 || p.Sort != "" || !reflect.DeepEqual(p.Tags, []string{"c"}) {
+		t.Fatalf("should not assign the defaults to present params: %#v", p)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/?sort=")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p = v.(*defaultParams); p.Page != 1 || p.Sort != "" {
+		t.Fatalf("should assign the defaults to absent params only: %#v", p)
+	}
+
+	type badDefault struct {
+		Page int `param:"in(query),default(one)"`
+	}
+	if _, err = NewParamsAPI(new(badDefault), nil, nil); err == nil {
+		t.Fatal("should fail for the unparsable default")
+	}
+	type requiredDefault struct {
+		Page int `param:"in(query),required,default(1)"`
+	}
+	if _, err = NewParamsAPI(new(requiredDefault), nil, nil); err == nil {
+		t.Fatal("should fail for the required param")
+	}
+}
+
+func TestHostname(t *testing.T) {
+	type hostnameParams struct {
+		Host   string `param:"in(query),hostname"`
+		Domain string `param:"in(query),fqdn"`
+	}
+	m, err := NewParamsAPI(new(hostnameParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?host=localhost&domain=api.example.com.", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	longLabel := strings.Repeat("a", 64)
+	for query, reason := range map[string]string{
+		"/?host=" + longLabel + "&domain=example.com": "host is not a valid hostname",
+		"/?host=my_host&domain=example.com":           "host is not a valid hostname",
+		"/?host=-host&domain=example.com":             "host is not a valid hostname",
+		"/?host=localhost&domain=localhost":           "domain is not a valid fqdn",
+		"/?host=localhost&domain=exa%24mple.com":      "domain is not a valid fqdn",
+		"/?host=localhost&domain=10.0.0.1":            "domain is not a valid fqdn",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err == nil || err.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+}
+
+func TestETagHeader(t *testing.T) {
+	type etagParams struct {
+		IfMatch     string   `param:"in(header),name(If-Match),required"`
+		IfNoneMatch []string `param:"in(header),name(If-None-Match)"`
+	}
+	m, err := NewParamsAPI(new(etagParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("PUT", "/", nil)
+	req.Header.Set("If-Match", `"33a64df5"`)
+	req.Header.Set("If-None-Match", `"a", W/"b"`)
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*etagParams)
+	if p.IfMatch != "33a64df5" {
+		t.Fatal("should strip the quotes", p.IfMatch)
+	}
+	if !reflect.DeepEqual(p.IfNoneMatch, []string{"a", "W/b"}) {
+		t.Fatal("should split the ETags", p.IfNoneMatch)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set("If-Match", `"33a64df5"`)
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p = v.(*etagParams); p.IfMatch != "33a64df5" {
+		t.Fatal("should strip the quotes", p.IfMatch)
+	}
+}
+
+func TestValidateJSONSchema(t *testing.T) {
+	type schemaParams struct {
+		Body map[string]interface{} `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(schemaParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := []byte(`{"type":"object","required":["name"]}`)
+	if err = m.ValidateJSONSchema(schema, []byte(`{"name":"a"}`)); err == nil {
+		t.Fatal("should fail without validator")
+	}
+	// a tiny validator that only understands `required`
+	m.SetJSONSchemaValidateFunc(func(schema, body []byte) error {
+		var s struct {
+			Required []string `json:"required"`
+		}
+		var b map[string]interface{}
+		if err := json.Unmarshal(schema, &s); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &b); err != nil {
+			return err
+		}
+		for _, k := range s.Required {
+			if _, ok := b[k]; !ok {
+				return errors.New("missing property `" + k + "`")
+			}
+		}
+		return nil
+	})
+	if err = m.ValidateJSONSchema(schema, []byte(`{"name":"a"}`)); err != nil {
+		t.Fatal("should validate", err)
+	}
+	err = m.ValidateJSONSchema(schema, []byte(`{"age":1}`))
+	if e, ok := err.(*Error); !ok || e.Param != "body" || e.Reason != "missing property `name`" {
+		t.Fatal("should not validate", err)
+	}
+}
+
+func TestBindHeaderOnly(t *testing.T) {
+	type headerOnlyParams struct {
+		Token string `param:"in(header),name(X-Token),required"`
+		Id    int    `param:"in(path)"`
+	}
+	m, err := NewParamsAPI(new(headerOnlyParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const body = "a=1&b=2"
+	req := httptest.NewRequest("POST", "/7", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Token", "abc")
+	p := new(headerOnlyParams)
+	if err = m.BindHeaderOnly(p, req, Map{"id": "7"}); err != nil {
+		t.Fatal("should bind", err)
+	}
+	if p.Token != "abc" || p.Id != 7 {
+		t.Fatal("wrong value", p)
+	}
+	if req.Form != nil {
+		t.Fatal("should not parse form")
+	}
+	if b, _ := ioutil.ReadAll(req.Body); string(b) != body {
+		t.Fatal("body should stay untouched", string(b))
+	}
+
+	type queryParams struct {
+		Token string `param:"in(header),name(X-Token)"`
+		Page  int    `param:"in(query)"`
+	}
+	m, _ = NewParamsAPI(new(queryParams), nil, nil)
+	if err = m.BindHeaderOnly(new(queryParams), httptest.NewRequest("GET", "/", nil), nil); err == nil {
+		t.Fatal("should not bind query param")
+	}
+}
+
+func TestBindParsesForm(t *testing.T) {
+	type queryParams struct {
+		Page int `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(queryParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/?page=2", strings.NewReader("a=1&b=2"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	p := new(queryParams)
+	if err = m.BindAt(p, req, nil); err != nil {
+		t.Fatal("should bind", err)
+	}
+	if p.Page != 2 {
+		t.Fatal("wrong value", p)
+	}
+	// the handler can read the form after binding, although no formData param is declared
+	if req.Form == nil || req.PostForm.Get("a") != "1" || req.Form.Get("page") != "2" {
+		t.Fatal("should parse form", req.Form)
+	}
+}
+
+func TestRequiredWith(t *testing.T) {
+	type addressParams struct {
+		Street string `param:"in(query)"`
+		Zip    string `param:"in(query)"`
+		City   string `param:"in(query),required_with(Street,Zip)"`
+	}
+	m, err := NewParamsAPI(new(addressParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, errStr := range map[string]string{
+		"street=a&city=b": "",
+		"":                "",
+		"city=b":          "",
+		"street=a":        "city not set",
+		"zip=1":           "city not set",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", "/?"+query, nil), nil)
+		if errStr == "" && err != nil {
+			t.Fatal("should validate", query, err)
+		}
+		if errStr != "" && (err == nil || err.Error() != errStr) {
+			t.Fatal("should not validate", query, err)
+		}
+	}
+
+	type badParams struct {
+		City string `param:"in(query),required_with(Street)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for unknown field")
+	}
+}
+
+func TestSortFields(t *testing.T) {
+	type listParams struct {
+		Sort []SortField `param:"in(query),sortfields(name,age,created)"`
+	}
+	m, err := NewParamsAPI(new(listParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?sort=name,-age&sort=created", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	want := []SortField{{"name", false}, {"age", true}, {"created", false}}
+	if sort := v.(*listParams).Sort; !reflect.DeepEqual(sort, want) {
+		t.Fatal("wrong value", sort)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?sort=name,-password", nil), nil)
+	if e, ok := err.(*Error); !ok || e.Reason != "sort field `password` is not allowed" {
+		t.Fatal("should not bind", err)
+	}
+
+	type badParams struct {
+		Sort []string `param:"in(query),sortfields(name)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-SortField field")
+	}
+}
+
+func TestKeyValuesKeepOrder(t *testing.T) {
+	type filterParams struct {
+		Filter []KeyValue `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(filterParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/?filter[z]=1&page=2&filter[a]=x%20y&filter[m]=3&filter=4", nil)
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []KeyValue{{"z", "1"}, {"a", "x y"}, {"m", "3"}}
+	if f := v.(*filterParams).Filter; !reflect.DeepEqual(f, want) {
+		t.Fatal("wrong value", f)
+	}
+
+	type badParams struct {
+		Filter []KeyValue `param:"in(header)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-query []KeyValue")
+	}
+}
+
+func TestNonzeroMap(t *testing.T) {
+	type nonzeroMapParams struct {
+		Filter map[string]string `param:"in(query),nonzero"`
+	}
+	m, err := NewParamsAPI(new(nonzeroMapParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?filter[name]=a", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/", nil), nil); err == nil || err.Error() != "filter not set" {
+		t.Fatal("should fail for the absent map instead of panicking", err)
+	}
+}
+
+func TestBracketMap(t *testing.T) {
+	type mapParams struct {
+		Filter map[string]string   `param:"in(query)"`
+		Tag    map[string][]string `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(mapParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const query = "/?filter[a]=1&filter[b]=2&filter[a]=3&tag[x]=1&tag[x]=2&tag[y]=3&page=1"
+	v, err := m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*mapParams)
+	if !reflect.DeepEqual(p.Filter, map[string]string{"a": "3", "b": "2"}) {
+		t.Fatal("wrong value", p.Filter)
+	}
+	if !reflect.DeepEqual(p.Tag, map[string][]string{"x": {"1", "2"}, "y": {"3"}}) {
+		t.Fatal("wrong value", p.Tag)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI(query)
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p = v.(*mapParams); !reflect.DeepEqual(p.Filter, map[string]string{"a": "3", "b": "2"}) {
+		t.Fatal("wrong value", p.Filter)
+	}
+
+	type badParams struct {
+		Filter map[string]string `param:"in(header)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-query map")
+	}
+	type bodyParams struct {
+		Body map[string]string `param:"in(body)"`
+	}
+	if _, err = NewParamsAPI(new(bodyParams), nil, nil); err != nil {
+		t.Fatal("should still decode the body into the map", err)
+	}
+}
+
+func TestBodyReader(t *testing.T) {
+	type streamParams struct {
+		ID   int       `param:"in(query)"`
+		Body io.Reader `param:"in(body),required"`
+	}
+	m, err := NewParamsAPI(new(streamParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("POST", "/?id=1", strings.NewReader("streaming body")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(v.(*streamParams).Body)
+	if err != nil || string(b) != "streaming body" {
+		t.Fatal("wrong value", string(b), err)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetBodyString("fasthttp body")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ = ioutil.ReadAll(v.(*streamParams).Body); string(b) != "fasthttp body" {
+		t.Fatal("wrong value", string(b))
+	}
+
+	type badParams struct {
+		Body io.Reader `param:"in(query)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-body io.Reader")
+	}
+}
+
+func TestBodyRawBytes(t *testing.T) {
+	type rawParams struct {
+		Signature string `param:"in(header),name(X-Signature)"`
+		Body      []byte `param:"in(body),required"`
+	}
+	m, err := NewParamsAPI(new(rawParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"event": "push"}`))
+	req.Header.Set("X-Signature", "sha256=abc")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*rawParams); string(p.Body) != `{"event": "push"}` || p.Signature != "sha256=abc" {
+		t.Fatal("wrong value", string(p.Body), p.Signature)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetBodyString("not json")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := v.(*rawParams).Body; string(b) != "not json" {
+		t.Fatal("wrong value", string(b))
+	}
+}
+
+func TestBodyStructPointer(t *testing.T) {
+	type bodyProfile struct {
+		Nickname string `json:"nickname"`
+	}
+	type profileParams struct {
+		ID      int          `param:"in(query)"`
+		Profile *bodyProfile `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(profileParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("PATCH", "/?id=1", strings.NewReader(`{"nickname":"go"}`)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*profileParams).Profile; p == nil || p.Nickname != "go" {
+		t.Fatal("wrong value", p)
+	}
+	v, err = m.BindNew(httptest.NewRequest("PATCH", "/?id=1", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*profileParams).Profile; p != nil {
+		t.Fatal("should stay nil for absent body", p)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	if v, err = m.FasthttpBindNew(ctx, nil); err != nil || v.(*profileParams).Profile != nil {
+		t.Fatal("should stay nil for absent body", err)
+	}
+	ctx.Request.SetBodyString(`{"nickname":"fast"}`)
+	if v, err = m.FasthttpBindNew(ctx, nil); err != nil || v.(*profileParams).Profile.Nickname != "fast" {
+		t.Fatal("should decode the body", err)
+	}
+
+	type requiredProfileParams struct {
+		Profile *bodyProfile `param:"in(body),required"`
+	}
+	m, err = NewParamsAPI(new(requiredProfileParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("PATCH", "/", nil), nil); err == nil {
+		t.Fatal("should fail for absent required body")
+	}
+
+	type badParams struct {
+		Profile *bodyProfile `param:"in(query)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-body struct pointer")
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	type depthParams struct {
+		Body interface{} `param:"in(body),maxdepth(3)"`
+	}
+	m, err := NewParamsAPI(new(depthParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("POST", "/", strings.NewReader(`{"a":[{"b":"[[[["}]}`)), nil); err != nil {
+		t.Fatal("should bind within the depth", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("POST", "/", strings.NewReader(`{"a":[{"b":[1]}]}`)), nil)
+	if err == nil || !strings.Contains(err.Error(), "JSON nesting depth exceeds 3") {
+		t.Fatal("should fail for exceeding the depth", err)
+	}
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetBodyString(`[[[[1]]]]`)
+	if _, err = m.FasthttpBindNew(ctx, nil); err == nil {
+		t.Fatal("should fail for exceeding the depth")
+	}
+
+	type badParams struct {
+		Name string `param:"in(query),maxdepth(3)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-body param")
+	}
+}
+
+func TestParsers(t *testing.T) {
+	RegisterTransform("reverse", func(s string) (string, error) {
+		r := []rune(s)
+		for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+			r[i], r[j] = r[j], r[i]
+		}
+		return string(r), nil
+	})
+	defer RegisterTransform("reverse", nil)
+	type parsersParams struct {
+		Code string `param:"in(query),parsers(base64|trim|lower|reverse)"`
+	}
+	m, err := NewParamsAPI(new(parsersParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// base64 of "  HeLLo "
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?code=ICBIZUxMbyA%3D", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := v.(*parsersParams).Code; code != "olleh" {
+		t.Fatal("wrong value", code)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?code=%21%21", nil), nil); err == nil {
+		t.Fatal("should fail for the invalid base64")
+	}
+
+	type badParams struct {
+		Code string `param:"in(query),parsers(trim|unknown)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for the unknown transform")
+	}
+
+	// the `trim`, `lower` and `upper` tags share the transforms of the same names
+	upper := transforms["upper"]
+	RegisterTransform("upper", func(s string) (string, error) {
+		s, _ = upper(s)
+		return strings.Replace(s, "-", "_", -1), nil
+	})
+	defer RegisterTransform("upper", upper)
+	type normalizeParams struct {
+		Code string `param:"in(query),trim,upper"`
+	}
+	if m, err = NewParamsAPI(new(normalizeParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if v, err = m.BindNew(httptest.NewRequest("GET", "/?code=+a-b+", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if code := v.(*normalizeParams).Code; code != "A_B" {
+		t.Fatal("should use the registered transform", code)
+	}
+}
+
+func TestBodySize(t *testing.T) {
+	type sizeParams struct {
+		Size int64             `param:"in(bodysize)"`
+		Body map[string]string `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(sizeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"name":"apiware"}`
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	reqBody := req.Body
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := v.(*sizeParams).Size; size != int64(len(body)) {
+		t.Fatal("wrong value", size)
+	}
+	if req.Body != reqBody {
+		t.Fatal("should restore the request body")
+	}
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetBodyString(body)
+	if v, err = m.FasthttpBindNew(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if size := v.(*sizeParams).Size; size != int64(len(body)) {
+		t.Fatal("wrong value", size)
+	}
+
+	type formSizeParams struct {
+		Size uint   `param:"in(bodysize),range(:1024)"`
+		Name string `param:"in(formData)"`
+	}
+	if m, err = NewParamsAPI(new(formSizeParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest("POST", "/", strings.NewReader("name=apiware"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if v, err = m.BindNew(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*formSizeParams); p.Size != uint(len("name=apiware")) || p.Name != "apiware" {
+		t.Fatal("wrong value", p)
+	}
+
+	type badParams struct {
+		Size string `param:"in(bodysize)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-integer field")
+	}
+}
+
+type testUUID [16]byte
+
+func TestUUID(t *testing.T) {
+	type uuidParams struct {
+		ID      string     `param:"in(path),name(id),uuid"`
+		Trace   testUUID   `param:"in(query)"`
+		Parents []testUUID `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(uuidParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/?trace=123e4567e89b12d3a456426614174000&parents=00000000-0000-0000-0000-000000000001", nil)
+	v, err := m.BindNew(req, Map{"id": "123E4567-E89B-12D3-A456-426614174000"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*uuidParams); p.Trace[0] != 0x12 || p.Trace[6] != 0x12 || len(p.Parents) != 1 || p.Parents[0][15] != 1 {
+		t.Fatal("wrong value", p)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/", nil), Map{"id": "123e4567-e89b-12d3-a456-42661417400"})
+	if err == nil || err.Error() != "id is not a valid uuid" {
+		t.Fatal("should fail for the invalid uuid", err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?trace=123e4567_e89b_12d3_a456_426614174000", nil), Map{"id": "123e4567e89b12d3a456426614174000"}); err == nil {
+		t.Fatal("should fail for the invalid uuid field")
+	}
+}
+
+func TestStrictPathParams(t *testing.T) {
+	type strictPathParams struct {
+		ID int `param:"in(path),name(id)"`
+	}
+	m, err := NewParamsAPI(new(strictPathParams), nil, nil, StrictPathParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/users/1", nil), Map{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/users/1/posts/2", nil), Map{"id": "1", "post_id": "2"})
+	if err == nil || !strings.Contains(err.Error(), "undeclared path param") {
+		t.Fatal("should fail for the undeclared path param", err)
+	}
+	if _, err = m.FasthttpBindNew(new(fasthttp.RequestCtx), Map{"id": "1", "post_id": "2"}); err == nil {
+		t.Fatal("should fail for the undeclared path param")
+	}
+
+	loose, err := NewParamsAPI(new(strictPathParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loose.BindNew(httptest.NewRequest("GET", "/users/1/posts/2", nil), Map{"id": "1", "post_id": "2"}); err != nil {
+		t.Fatal("should ignore the undeclared path param by default", err)
+	}
+}
+
+func TestEmailAndURL(t *testing.T) {
+	type contactParams struct {
+		Email    string   `param:"in(formData),email"`
+		Homepage string   `param:"in(formData),url" err:"homepage must be an absolute URL"`
+		Mirrors  []string `param:"in(formData),url"`
+	}
+	m, err := NewParamsAPI(new(contactParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(values url.Values) error {
+		form := url.Values{"email": {"gopher@example.com"}, "homepage": {"https://example.com/a?b=c"}, "mirrors": {"http://a.io"}}
+		for k, v := range values {
+			form[k] = v
+		}
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := m.BindNew(req, nil)
+		return err
+	}
+	if err = bind(nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		values url.Values
+		err    string
+	}{
+		{url.Values{"email": {"gopher"}}, "email is not a valid email"},
+		{url.Values{"email": {"Gopher <gopher@example.com>"}}, "email is not a valid email"},
+		{url.Values{"homepage": {"/a/b"}}, "homepage must be an absolute URL"},
+		{url.Values{"mirrors": {"http://a.io", "a.io"}}, "mirrors[1] is not a valid url"},
+	} {
+		if err = bind(c.values); err == nil || err.Error() != c.err {
+			t.Fatalf("%v: should fail with %q, got %v", c.values, c.err, err)
+		}
+	}
+}
+
+type testOrderItem struct {
+	SKU string
+	Qty int
+}
+
+func TestStructSliceElements(t *testing.T) {
+	type jsonItem struct {
+		SKU string `json:"sku"`
+		Qty int    `json:"qty"`
+	}
+	RegisterConverter(reflect.TypeOf(testOrderItem{}), func(dst reflect.Value, raw []string) error {
+		i := strings.LastIndexByte(raw[0], ':')
+		if i < 0 {
+			return errors.New("want sku:qty")
+		}
+		qty, err := strconv.Atoi(raw[0][i+1:])
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(testOrderItem{SKU: raw[0][:i], Qty: qty}))
+		return nil
+	})
+	defer RegisterConverter(reflect.TypeOf(testOrderItem{}), nil)
+	type orderParams struct {
+		Items []testOrderItem `param:"in(query),name(item)"`
+		Extra []jsonItem      `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(orderParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := url.Values{"item": {"a-1:2", "b-2:1"}, "extra": {`{"sku":"c-3","qty":5}`, `{"sku":"d-4","qty":1}`}}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?"+q.Encode(), nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*orderParams)
+	if len(p.Items) != 2 || p.Items[1] != (testOrderItem{"b-2", 1}) {
+		t.Fatal("wrong value", p.Items)
+	}
+	if len(p.Extra) != 2 || p.Extra[0] != (jsonItem{"c-3", 5}) {
+		t.Fatal("wrong value", p.Extra)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?item=a-1", nil), nil); err == nil {
+		t.Fatal("should fail for the converter error")
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?extra=x", nil), nil); err == nil {
+		t.Fatal("should fail for the invalid JSON element")
+	}
+}
+
+func TestASCII(t *testing.T) {
+	type asciiParams struct {
+		Token string   `param:"in(query),ascii"`
+		Tags  []string `param:"in(query),ascii"`
+	}
+	m, err := NewParamsAPI(new(asciiParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?token=abc-123_~%21&tags=a", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?token=ab%C3%A9c&tags=a", nil), nil)
+	if err == nil || err.Error() != "token must be ASCII only, but has a non-ASCII byte at position 2" {
+		t.Fatal("should fail for the unicode input", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?token=a&tags=a&tags=%E4%B8%AD", nil), nil)
+	if err == nil || err.Error() != "tags[1] must be ASCII only, but has a non-ASCII byte at position 0" {
+		t.Fatal("should fail for the unicode element", err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	type durationParams struct {
+		TTL      time.Duration   `param:"in(query),name(ttl),range(1s:1h)"`
+		Timeout  *time.Duration  `param:"in(query),max(30s)"`
+		Backoffs []time.Duration `param:"in(query),positive"`
+	}
+	m, err := NewParamsAPI(new(durationParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?ttl=30s&timeout=1.5s&backoffs=100ms&backoffs=1m", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*durationParams)
+	if p.TTL != 30*time.Second || p.Timeout == nil || *p.Timeout != 1500*time.Millisecond {
+		t.Fatal("wrong values", p.TTL, p.Timeout)
+	}
+	if !reflect.DeepEqual(p.Backoffs, []time.Duration{100 * time.Millisecond, time.Minute}) {
+		t.Fatal("wrong elements", p.Backoffs)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?ttl=2h&backoffs=1s", nil), nil); err == nil || err.Error() != "ttl too big" {
+		t.Fatal("should fail for the out of range duration", err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?ttl=1m&timeout=1m&backoffs=1s", nil), nil); err == nil || err.Error() != "timeout must be <= 30s" {
+		t.Fatal("should fail for the too long timeout", err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?ttl=1m&backoffs=1s&backoffs=-1s", nil), nil); err == nil || err.Error() != "backoffs[1] must be > 0" {
+		t.Fatal("should fail for the negative element", err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?ttl=soon&backoffs=1s", nil), nil); err == nil || !strings.Contains(err.Error(), "time.Duration") {
+		t.Fatal("should fail for the unparseable duration", err)
+	}
+
+	type badDurationParams struct {
+		TTL time.Duration `param:"in(query),name(ttl),max(10)"`
+	}
+	if _, err = NewParamsAPI(new(badDurationParams), nil, nil); err == nil || !strings.Contains(err.Error(), "is not a duration") {
+		t.Fatal("should reject the non-duration bound", err)
+	}
+}
+
+func TestSkipAndDashName(t *testing.T) {
+	type skipParams struct {
+		Dash    string `param:"in(query),name(-)"`
+		Skipped string `param:"skip"`
+		Ignored string `param:"-"`
+	}
+	m, err := NewParamsAPI(new(skipParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(m.Params()); n != 1 {
+		t.Fatal("should only register the dash-named param", n)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?-=a&skipped=b&ignored=c", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*skipParams); p.Dash != "a" || p.Skipped != "" || p.Ignored != "" {
+		t.Fatal("wrong values", p)
+	}
+}
+
+func TestE164(t *testing.T) {
+	type phoneParams struct {
+		Phone  string   `param:"in(query),phone"`
+		Backup []string `param:"in(query),e164" err:"backup must be like +14155552671"`
+	}
+	m, err := NewParamsAPI(new(phoneParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, phone := range []string{"%2B14155552671", "%2B442071838750", "%2B861"} {
+		if _, err = m.BindNew(httptest.NewRequest("GET", "/?phone="+phone+"&backup=%2B8613800138000", nil), nil); err != nil {
+			t.Fatal(phone, err)
+		}
+	}
+	for _, phone := range []string{"14155552671", "%2B04155552671", "%2B1415555267100000", "%2B1-415-555-2671", "%2B"} {
+		_, err = m.BindNew(httptest.NewRequest("GET", "/?phone="+phone+"&backup=%2B8613800138000", nil), nil)
+		if err == nil || err.Error() != "phone is not a valid E.164 phone number" {
+			t.Fatal("should fail for", phone, err)
+		}
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?phone=%2B14155552671&backup=%2B8613800138000&backup=555", nil), nil)
+	if err == nil || err.Error() != "backup must be like +14155552671" {
+		t.Fatal("should use the custom error", err)
+	}
+}
+
+func TestEndian(t *testing.T) {
+	type endianParams struct {
+		Big    uint32   `param:"in(query),endian(big)"`
+		Little uint32   `param:"in(query),endian(little)"`
+		Signed int16    `param:"in(query),endian(big)"`
+		Short  *uint64  `param:"in(query),endian(little)"`
+		Words  []uint16 `param:"in(query),endian(big)"`
+	}
+	m, err := NewParamsAPI(new(endianParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?big=0x01020304&little=AQIDBA%3D%3D&signed=fffe&short=ff&words=0102&words=ff00", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*endianParams)
+	if p.Big != 0x01020304 || p.Little != 0x04030201 || p.Signed != -2 {
+		t.Fatalf("wrong values %#x %#x %d", p.Big, p.Little, p.Signed)
+	}
+	if p.Short == nil || *p.Short != 0xff {
+		t.Fatal("should zero-extend the short little endian value", p.Short)
+	}
+	if !reflect.DeepEqual(p.Words, []uint16{0x0102, 0xff00}) {
+		t.Fatal("wrong elements", p.Words)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?signed=010203", nil), nil); err == nil || !strings.Contains(err.Error(), "overflow") {
+		t.Fatal("should fail for the overflowing bytes", err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?big=zz!", nil), nil); err == nil {
+		t.Fatal("should fail for the invalid bytes")
+	}
+
+	type badEndianParams struct {
+		Name string `param:"in(query),endian(big)"`
+	}
+	if _, err = NewParamsAPI(new(badEndianParams), nil, nil); err == nil {
+		t.Fatal("should reject the `endian` tag on non-integer field")
+	}
+	type badOrderParams struct {
+		N uint16 `param:"in(query),endian(middle)"`
+	}
+	if _, err = NewParamsAPI(new(badOrderParams), nil, nil); err == nil {
+		t.Fatal("should reject the invalid byte order")
+	}
+}
+
+func TestLenientBool(t *testing.T) {
+	type lenientParams struct {
+		Cache   bool  `param:"in(query),default(true)"`
+		Debug   bool  `param:"in(query)"`
+		Verbose *bool `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(lenientParams), nil, nil, LenientBool())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?cache=maybe&debug=yes!&verbose=", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*lenientParams); !p.Cache || p.Debug || p.Verbose != nil {
+		t.Fatal("should fall back to the defaults", p)
+	}
+	v, err = m.BindNew(httptest.NewRequest("GET", "/?cache=off&debug=on&verbose=0", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*lenientParams); p.Cache || !p.Debug || *p.Verbose {
+		t.Fatal("should parse the valid words", p)
+	}
+
+	strict, err := NewParamsAPI(new(lenientParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, err = strict.BindNew(httptest.NewRequest("GET", "/?cache=maybe", nil), nil); err != nil || v.(*lenientParams).Cache {
+		t.Fatal("should be false by default", err)
+	}
+}
+
+func TestHasBodyFormDataFile(t *testing.T) {
+	type queryOnlyParams struct {
+		Page int `param:"in(query)"`
+	}
+	type bodyParams struct {
+		Body map[string]string `param:"in(body)"`
+	}
+	type formParams struct {
+		Name string `param:"in(formData)"`
+	}
+	type fallbackParams struct {
+		Token string `param:"fallback(header:X-Token,formData:token)"`
+	}
+	type uploadParams struct {
+		Photos []*multipart.FileHeader `param:"in(formData)"`
+	}
+	for _, c := range []struct {
+		structPointer             interface{}
+		hasBody, hasForm, hasFile bool
+	}{
+		{new(queryOnlyParams), false, false, false},
+		{new(bodyParams), true, false, false},
+		{new(formParams), false, true, false},
+		{new(fallbackParams), false, true, false},
+		{new(uploadParams), false, true, true},
+	} {
+		m, err := NewParamsAPI(c.structPointer, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.HasBody() != c.hasBody || m.HasFormData() != c.hasForm || m.HasFile() != c.hasFile {
+			t.Fatalf("%T: wrong flags %v %v %v", c.structPointer, m.HasBody(), m.HasFormData(), m.HasFile())
+		}
+	}
+}
+
+func TestBindPooled(t *testing.T) {
+	type pooledParams struct {
+		Page int      `param:"in(query)"`
+		Tags []string `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(pooledParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindPooled(httptest.NewRequest("GET", "/?page=2&tags=a&tags=b", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*pooledParams); p.Page != 2 || len(p.Tags) != 2 {
+		t.Fatal("wrong value", p)
+	}
+	m.Release(v)
+	m.Release(new(int))
+	for i := 0; i < 3; i++ {
+		v, err = m.BindPooled(httptest.NewRequest("GET", "/", nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p := v.(*pooledParams); p.Page != 0 || p.Tags != nil {
+			t.Fatal("should reset the released struct", p)
+		}
+		m.Release(v)
+	}
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/?page=3")
+	if v, err = m.FasthttpBindPooled(ctx, nil); err != nil || v.(*pooledParams).Page != 3 {
+		t.Fatal("wrong value", v, err)
+	}
+	m.Release(v)
+}
+
+func TestSemver(t *testing.T) {
+	type semverParams struct {
+		Version string `param:"in(query),semver"`
+		Client  string `param:"in(query),semver(>=1.2.0 <2.0.0)"`
+	}
+	m, err := NewParamsAPI(new(semverParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"/?version=1.0.0&client=1.2.0",
+		"/?version=0.1.0-alpha.1%2Bbuild.5&client=1.9.9",
+		"/?version=10.20.30&client=1.2.1-rc.1",
+	} {
+		if _, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil); err != nil {
+			t.Fatalf("%s: should bind, got %v", query, err)
+		}
+	}
+	for query, reason := range map[string]string{
+		"/?version=1.0&client=1.2.0":           "version is not a valid semver",
+		"/?version=01.0.0&client=1.2.0":        "version is not a valid semver",
+		"/?version=1.0.0-&client=1.2.0":        "version is not a valid semver",
+		"/?version=1.0.0&client=1.1.9":         "client must be >= 1.2.0",
+		"/?version=1.0.0&client=1.2.0-beta":    "client must be >= 1.2.0",
+		"/?version=1.0.0&client=2.0.0":         "client must be < 2.0.0",
+		"/?version=1.0.0&client=not-a-version": "client is not a valid semver",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err == nil || err.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+
+	type badParams struct {
+		Client string `param:"in(query),semver(>=1.2)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for the malformed constraint")
+	}
+}
+
+func TestBodySliceAndMap(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type listParams struct {
+		Items []item `param:"in(body)"`
+	}
+	type mapParams struct {
+		Scores map[string]int `param:"in(body)"`
+	}
+	lm, err := NewParamsAPI(new(listParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mm, err := NewParamsAPI(new(mapParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := lm.BindNew(httptest.NewRequest("POST", "/", strings.NewReader(`[{"id":1,"name":"a"},{"id":2,"name":"b"}]`)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items := v.(*listParams).Items; !reflect.DeepEqual(items, []item{{1, "a"}, {2, "b"}}) {
+		t.Fatal("wrong value", items)
+	}
+	v, err = mm.BindNew(httptest.NewRequest("POST", "/", strings.NewReader(`{"a":1,"b":2}`)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scores := v.(*mapParams).Scores; !reflect.DeepEqual(scores, map[string]int{"a": 1, "b": 2}) {
+		t.Fatal("wrong value", scores)
+	}
+	if _, err = mm.BindNew(httptest.NewRequest("POST", "/", strings.NewReader(`[1,2]`)), nil); err == nil {
+		t.Fatal("should not decode an array into the map")
+	}
+}
+
+// decodeLatin1 decodes the ISO-8859-1 string to UTF-8.
+func decodeLatin1(s string) (string, error) {
+	r := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		r[i] = rune(s[i])
+	}
+	return string(r), nil
+}
+
+func TestCharset(t *testing.T) {
+	type charsetParams struct {
+		Name string `param:"in(query)"`
+		City string `param:"in(formData)"`
+	}
+	m, err := NewParamsAPI(new(charsetParams), nil, nil, Charset(decodeLatin1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/?name=caf%E9", strings.NewReader("city=M%FCnchen"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*charsetParams); p.Name != "café" || p.City != "München" {
+		t.Fatal("should decode to UTF-8", p.Name, p.City)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/?name=caf%E9")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*charsetParams); p.Name != "café" {
+		t.Fatal("should decode to UTF-8", p.Name)
+	}
+}
+
+func TestBindByName(t *testing.T) {
+	type byNameParams struct {
+		ID   int    `param:"in(path),name(id)"`
+		Name string `param:"in(query)"`
+	}
+	if err := Register(new(byNameParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	v, err := BindByName("*apiware.byNameParams", httptest.NewRequest("GET", "/?name=abc", nil), Map{"id": "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := v.(*byNameParams)
+	if !ok {
+		t.Fatalf("wrong type %T", v)
+	}
+	if p.ID != 5 || p.Name != "abc" {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	if _, err = BindByName("*apiware.unknownParams", httptest.NewRequest("GET", "/", nil), nil); err == nil {
+		t.Fatal("should fail for the unregistered struct")
+	}
+}
+
+func TestRequiredMethods(t *testing.T) {
+	type articleParams struct {
+		ID    int    `param:"in(query)"`
+		Title string `param:"in(formData),required_methods(POST,put)"`
+	}
+	m, err := NewParamsAPI(new(articleParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?id=1", nil), nil); err != nil {
+		t.Fatal("should be optional for GET", err)
+	}
+	for _, method := range []string{"POST", "PUT"} {
+		req := httptest.NewRequest(method, "/?id=1", strings.NewReader("body=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err = m.BindNew(req, nil)
+		if e, ok := err.(*Error); !ok || e.Param != "title" || e.Reason != "missing formData param" {
+			t.Fatalf("%s: should be required, got %v", method, err)
+		}
+	}
+	req := httptest.NewRequest("POST", "/?id=1", strings.NewReader("title=hello"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*articleParams); p.Title != "hello" {
+		t.Fatal("wrong value", p.Title)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod("POST")
+	if _, err = m.FasthttpBindNew(ctx, nil); err == nil {
+		t.Fatal("should be required for POST")
+	}
+
+	type badParams struct {
+		Title string `param:"in(query),required,required_methods(POST)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail with the `required` tag")
+	}
+}
+
+func TestNumberSign(t *testing.T) {
+	type signParams struct {
+		Count  int     `param:"in(query),positive"`
+		Debt   float64 `param:"in(query),negative"`
+		Offset []int   `param:"in(query),nonnegative"`
+		Delta  *int8   `param:"in(query),positive"`
+	}
+	m, err := NewParamsAPI(new(signParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?count=1&debt=-0.5&offset=0&offset=3&delta=2", nil), nil)
+	if err != nil {
+		t.Fatal("should bind", err)
+	}
+	for query, reason := range map[string]string{
+		"/?count=0&debt=-1":                    "count must be > 0",
+		"/?count=-1&debt=-1":                   "count must be > 0",
+		"/?count=1&debt=0":                     "debt must be < 0",
+		"/?count=1&debt=2":                     "debt must be < 0",
+		"/?count=1&debt=-1&offset=0&offset=-1": "offset[1] must be >= 0",
+		"/?count=1&debt=-1&delta=0":            "delta must be > 0",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if e, ok := err.(*ValidationError); !ok || e.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?count=0&debt=-1", nil), nil)
+	if e := err.(*ValidationError); e.Kind() != ValidationErrorValueTooSmall {
+		t.Fatal("wrong kind", e.Kind())
+	}
+
+	type badParams struct {
+		Name string `param:"in(query),positive"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-number field")
+	}
+}
+
+// cancelReader cancels the context after the first read.
+type cancelReader struct {
+	cancel func()
+	read   bool
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, io.EOF
+	}
+	r.read = true
+	r.cancel()
+	return copy(p, `{"name":`), nil
+}
+
+func TestBindContext(t *testing.T) {
+	type contextParams struct {
+		ID   int               `param:"in(query),name(id)"`
+		Body map[string]string `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(contextParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNewContext(context.Background(), httptest.NewRequest("POST", "/?id=1", strings.NewReader(`{"name":"a"}`)), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*contextParams); p.ID != 1 || p.Body["name"] != "a" {
+		t.Fatalf("wrong value: %#v", p)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = m.BindNewContext(ctx, httptest.NewRequest("POST", "/?id=1", strings.NewReader(`{}`)), nil)
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatal("should be canceled", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest("POST", "/?id=1", nil)
+	req.Body = ioutil.NopCloser(&cancelReader{cancel: cancel})
+	_, err = m.BindNewContext(ctx, req, nil)
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Fatal("should be canceled while reading the body", err)
+	}
+}
+
+type ctxKey struct{}
+
+type ctxTenant string
+
+func TestBindContextHooks(t *testing.T) {
+	RegisterConverterContext(reflect.TypeOf(ctxTenant("")), func(ctx context.Context, dst reflect.Value, raw []string) error {
+		prefix, _ := ctx.Value(ctxKey{}).(string)
+		dst.SetString(prefix + raw[0])
+		return nil
+	})
+	defer RegisterConverterContext(reflect.TypeOf(ctxTenant("")), nil)
+	type hookParams struct {
+		Tenant ctxTenant         `param:"in(query)"`
+		Body   map[string]string `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(hookParams), nil, nil, BodyDecodeContext(func(ctx context.Context, dest reflect.Value, body []byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		prefix, _ := ctx.Value(ctxKey{}).(string)
+		dest.Set(reflect.ValueOf(map[string]string{"body": prefix + string(body)}))
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "ctx:")
+	v, err := m.BindNewContext(ctx, httptest.NewRequest("POST", "/?tenant=a", strings.NewReader("b")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*hookParams); p.Tenant != "ctx:a" || p.Body["body"] != "ctx:b" {
+		t.Fatalf("should pass ctx to the hooks: %#v", p)
+	}
+	// the request's context without BindFieldsContext
+	req := httptest.NewRequest("POST", "/?tenant=a", strings.NewReader("b")).WithContext(context.WithValue(context.Background(), ctxKey{}, "req:"))
+	if v, err = m.BindNew(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*hookParams); p.Tenant != "req:a" || p.Body["body"] != "req:b" {
+		t.Fatalf("should pass the request's context to the hooks: %#v", p)
+	}
+}
+
+func TestBindFieldsContextKeepsForm(t *testing.T) {
+	type formContextParams struct {
+		Name string `param:"in(formData)"`
+	}
+	m, err := NewParamsAPI(new(formContextParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/?page=2", strings.NewReader("name=a"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err = m.BindNewContext(context.Background(), req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if req.PostForm.Get("name") != "a" || req.Form.Get("page") != "2" {
+		t.Fatal("should keep the parsed form in req", req.Form, req.PostForm)
+	}
+}
+
+func TestMaxBody(t *testing.T) {
+	type maxBodyParams struct {
+		Body []byte `param:"in(body),maxbody(1)"`
+	}
+	m, err := NewParamsAPI(new(maxBodyParams), nil, func(dest reflect.Value, body []byte) error {
+		dest.SetBytes(body)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.MaxBody() != MB {
+		t.Fatal("wrong max body", m.MaxBody())
+	}
+	v, err := m.BindNew(httptest.NewRequest("POST", "/", bytes.NewReader(make([]byte, MB))), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := v.(*maxBodyParams).Body; len(b) != MB {
+		t.Fatal("wrong body size", len(b))
+	}
+	_, err = m.BindNew(httptest.NewRequest("POST", "/", bytes.NewReader(make([]byte, MB+1))), nil)
+	if e, ok := err.(*Error); !ok || e.Reason != "body too large" {
+		t.Fatal("should not bind", err)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetBody(make([]byte, MB+1))
+	_, err = m.FasthttpBindNew(ctx, nil)
+	if e, ok := err.(*Error); !ok || e.Reason != "body too large" {
+		t.Fatal("should not bind", err)
+	}
+
+	type defaultParams struct {
+		Body map[string]int `param:"in(body)"`
+	}
+	if m, err = NewParamsAPI(new(defaultParams), nil, nil); err != nil || m.MaxBody() != 32*MB {
+		t.Fatal("wrong default max body", m.MaxBody(), err)
+	}
+}
+
+func TestPresenceFlag(t *testing.T) {
+	type flagParams struct {
+		Verbose bool `param:"in(query),presence"`
+		Strict  bool `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(flagParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, want := range map[string][2]bool{
+		"/?verbose":        {true, false},
+		"/?verbose=":       {true, false},
+		"/?verbose=true":   {true, false},
+		"/?verbose=false":  {false, false},
+		"/?verbose&strict": {true, false},
+		"/?strict=1":       {false, true},
+		"/":                {false, false},
+	} {
+		v, err := m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := v.(*flagParams)
+		if [2]bool{p.Verbose, p.Strict} != want {
+			t.Fatalf("net/http %s: got %v, %v", query, p.Verbose, p.Strict)
+		}
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI(query)
+		v, err = m.FasthttpBindNew(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p = v.(*flagParams)
+		if [2]bool{p.Verbose, p.Strict} != want {
+			t.Fatalf("fasthttp %s: got %v, %v", query, p.Verbose, p.Strict)
+		}
+	}
+
+	type badParams struct {
+		Verbose string `param:"in(query),presence"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-bool field")
+	}
+}
+
+func TestNoValueQuery(t *testing.T) {
+	type nameParams struct {
+		Name string `param:"in(query),default(guest)"`
+		Tag  string `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(nameParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, want := range map[string][2]string{
+		"/?name":          {"guest", ""},
+		"/?name=":         {"", ""},
+		"/?name=bob":      {"bob", ""},
+		"/?name&tag":      {"guest", ""},
+		"/?tag=a":         {"guest", "a"},
+		"/?name=bob&name": {"bob", ""},
+	} {
+		v, err := m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := v.(*nameParams)
+		if [2]string{p.Name, p.Tag} != want {
+			t.Fatalf("net/http %s: got %q, %q", query, p.Name, p.Tag)
+		}
+
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.SetRequestURI(query)
+		v, err = m.FasthttpBindNew(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p = v.(*nameParams)
+		if [2]string{p.Name, p.Tag} != want {
+			t.Fatalf("fasthttp %s: got %q, %q", query, p.Name, p.Tag)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	type describeParams struct {
+		ID    int    `param:"in(path),name(id),desc(the user id)"`
+		Query string `param:"in(query),name(q),desc(search keywords)"`
+		Page  int    `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(describeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"id": "the user id", "q": "search keywords", "page": ""}
+	if descs := m.Describe(); !reflect.DeepEqual(descs, want) {
+		t.Fatal("wrong descriptions", descs)
+	}
+}
+
+func TestEqField(t *testing.T) {
+	type passwordParams struct {
+		OldPassword string `param:"in(formData),name(old_password)"`
+		Password    string `param:"in(formData),name(password),nefield(OldPassword)"`
+		Confirm     string `param:"in(formData),name(confirm),eqfield(Password)"`
+		Repeat      string `param:"in(formData),name(repeat),eqfield(Password)" err:"the passwords do not match"`
+	}
+	m, err := NewParamsAPI(new(passwordParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(form string) error {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := m.BindNew(req, nil)
+		return err
+	}
+	if err = bind("old_password=a&password=b&confirm=b&repeat=b"); err != nil {
+		t.Fatal("should bind", err)
+	}
+	for form, reason := range map[string]string{
+		"old_password=a&password=b&confirm=c&repeat=b": "confirm must equal password",
+		"old_password=a&password=a&confirm=a&repeat=a": "password must not equal old_password",
+		"old_password=a&password=b&confirm=b&repeat=c": "the passwords do not match",
+	} {
+		if err = bind(form); err == nil || err.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", form, err)
+		}
+	}
+
+	type unknownField struct {
+		Confirm string `param:"in(formData),eqfield(Passwd)"`
+	}
+	if _, err = NewParamsAPI(new(unknownField), nil, nil); err == nil {
+		t.Fatal("should fail for the unknown field")
+	}
+	type typeMismatch struct {
+		Password string `param:"in(formData)"`
+		Confirm  int    `param:"in(formData),eqfield(Password)"`
+	}
+	if _, err = NewParamsAPI(new(typeMismatch), nil, nil); err == nil {
+		t.Fatal("should fail for the different types")
+	}
+}
+
+func TestRequiredIf(t *testing.T) {
+	type leaveParams struct {
+		Reason      string `param:"in(query)"`
+		OtherReason string `param:"in(query),required_if(Reason,other)"`
+		Days        int    `param:"in(query)"`
+		Approver    string `param:"in(query),required_unless(Days,1)"`
+	}
+	m, err := NewParamsAPI(new(leaveParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"/?reason=sick&days=1",
+		"/?reason=other&other_reason=moving&days=1",
+		"/?reason=sick&days=3&approver=bob",
+	} {
+		if _, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil); err != nil {
+			t.Fatalf("%s: should bind, got %v", query, err)
+		}
+	}
+	for query, param := range map[string]string{
+		"/?reason=other&days=1":              "other_reason",
+		"/?reason=sick&days=3":               "approver",
+		"/?reason=other&days=3&approver=bob": "other_reason",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if e, ok := err.(*Error); !ok || e.Param != param || e.Reason != "missing required param" {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+
+	type badParams struct {
+		OtherReason string `param:"in(query),required_if(Reason)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for the malformed condition")
+	}
+}
+
+// pathKV is a KV which can not list its keys.
+type pathKV map[string]string
+
+func (kv pathKV) Get(k string) (string, bool) {
+	v, ok := kv[k]
+	return v, ok
+}
+
+func TestPathMap(t *testing.T) {
+	type routeParams struct {
+		Path map[string]string `param:"in(path)"`
+		ID   int               `param:"in(path),name(id)"`
+	}
+	m, err := NewParamsAPI(new(routeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pathParams := Map{"id": "3", "owner": "henrylee2cn", "repo": "apiware"}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/", nil), pathParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*routeParams)
+	if !reflect.DeepEqual(p.Path, map[string]string(pathParams)) || p.ID != 3 {
+		t.Fatalf("wrong value: %#v", p)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	v, err = m.FasthttpBindNew(ctx, pathParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p = v.(*routeParams); !reflect.DeepEqual(p.Path, map[string]string(pathParams)) {
+		t.Fatalf("wrong value: %#v", p)
+	}
+
+	_, err = m.BindNew(httptest.NewRequest("GET", "/", nil), pathKV{"id": "3"})
+	if e, ok := err.(*Error); !ok || e.Param != "path" {
+		t.Fatal("should fail for the KV without keys", err)
+	}
+}
+
+func TestChecksumCodes(t *testing.T) {
+	type bookParams struct {
+		ISBN    string `param:"in(query),name(isbn),isbn"`
+		Barcode string `param:"in(query),ean13" err:"invalid barcode"`
+	}
+	m, err := NewParamsAPI(new(bookParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range []string{
+		"/?isbn=0-306-40615-2&barcode=4006381333931",
+		"/?isbn=978-0-306-40615-7&barcode=4006381333931",
+		"/?isbn=080442957X&barcode=9780306406157",
+	} {
+		if _, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil); err != nil {
+			t.Fatalf("%s: should bind, got %v", query, err)
+		}
+	}
+	for query, reason := range map[string]string{
+		"/?isbn=0-306-40615-3&barcode=4006381333931":     "isbn is not a valid isbn",
+		"/?isbn=978-0-306-40615-8&barcode=4006381333931": "isbn is not a valid isbn",
+		"/?isbn=030640615&barcode=4006381333931":         "isbn is not a valid isbn",
+		"/?isbn=0306406152&barcode=4006381333932":        "invalid barcode",
+		"/?isbn=0306406152&barcode=400638133393A":        "invalid barcode",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", query, nil), nil)
+		if err == nil || err.Error() != reason {
+			t.Fatalf("%s: should not validate, got %v", query, err)
+		}
+	}
+}
+
+func TestOptionalBaseTypes(t *testing.T) {
+	type patchParams struct {
+		Name   *string `param:"in(formData),len(1:8)"`
+		Active *bool   `param:"in(formData)"`
+		Age    *int    `param:"in(formData),range(0:150)"`
+		Nick   *string `param:"in(formData),required_if(Active,true)"`
+	}
+	m, err := NewParamsAPI(new(patchParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(form string) (*patchParams, error) {
+		req := httptest.NewRequest("PATCH", "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		v, err := m.BindNew(req, nil)
+		return v.(*patchParams), err
+	}
+	p, err := bind("age=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != nil || p.Active != nil || p.Nick != nil || p.Age == nil || *p.Age != 0 {
+		t.Fatalf("only the present params should be set: %#v", p)
+	}
+	p, err = bind("name=&active=false")
+	if err == nil || err.Error() != "name too short" {
+		t.Fatal("should validate the present string", err)
+	}
+	p, err = bind("name=bob&active=false")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p.Name != "bob" || *p.Active {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	if _, err = bind("active=true"); err == nil {
+		t.Fatal("should require nick when active")
+	}
+}
+
+func TestSplit(t *testing.T) {
+	type splitParams struct {
+		Tags []string `param:"in(query),name(p),split(,)"`
+		IDs  []int    `param:"in(query),name(ids),split(|)"`
+		Raw  []string `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(splitParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?p=a,b&p=c&ids=1|2|3&raw=x,y", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*splitParams)
+	if !reflect.DeepEqual(p.Tags, []string{"a", "b", "c"}) || !reflect.DeepEqual(p.IDs, []int{1, 2, 3}) {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	if !reflect.DeepEqual(p.Raw, []string{"x,y"}) {
+		t.Fatal("should not split without the tag", p.Raw)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?ids=1|x", nil), nil); err == nil {
+		t.Fatal("should not bind")
+	}
+
+	type badParams struct {
+		Tag string `param:"in(query),split(,)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-slice field")
+	}
+}
+
+func TestFallbackSources(t *testing.T) {
+	type tokenParams struct {
+		Token string `param:"fallback(header:X-Token,query:token),required"`
+	}
+	m, err := NewParamsAPI(new(tokenParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if in := m.params[0].In(); in != "header" {
+		t.Fatal("wrong position", in)
+	}
+
+	req := httptest.NewRequest("GET", "/?token=q", nil)
+	req.Header.Set("X-Token", "h")
+	v, err := m.BindNew(req, nil)
+	if err != nil || v.(*tokenParams).Token != "h" {
+		t.Fatal("should bind from header", v, err)
+	}
+
+	v, err = m.BindNew(httptest.NewRequest("GET", "/?token=q", nil), nil)
+	if err != nil || v.(*tokenParams).Token != "q" {
+		t.Fatal("should fall back to query", v, err)
+	}
+
+	_, err = m.BindNew(httptest.NewRequest("GET", "/", nil), nil)
+	if e, ok := err.(*Error); !ok || e.Reason != "missing param" {
+		t.Fatal("should not bind", err)
+	}
+
+	type badParams struct {
+		Token string `param:"fallback(header:X-Token,body)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for body source")
+	}
+}
+
+type testFile struct {
+	field, filename, content string
+}
+
+// newMultipartBody returns a multipart/form-data body and its Content-Type.
+func newMultipartBody(values map[string]string, files ...testFile) ([]byte, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for k, v := range values {
+		w.WriteField(k, v)
+	}
+	for _, f := range files {
+		fw, _ := w.CreateFormFile(f.field, f.filename)
+		fw.Write([]byte(f.content))
+	}
+	w.Close()
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+func newMultipartRequest(values map[string]string, files ...testFile) *http.Request {
+	body, contentType := newMultipartBody(values, files...)
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func newFasthttpMultipartCtx(values map[string]string, files ...testFile) *fasthttp.RequestCtx {
+	body, contentType := newMultipartBody(values, files...)
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.Header.SetContentType(contentType)
+	ctx.Request.SetBody(body)
+	return ctx
+}
+
+func TestNonemptyFile(t *testing.T) {
+	type uploadParams struct {
+		Avatar multipart.FileHeader `param:"in(formData),required,nonempty"`
+	}
+	m, err := NewParamsAPI(new(uploadParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(newMultipartRequest(nil, testFile{"avatar", "a.png", "png"}), nil)
+	if err != nil || v.(*uploadParams).Avatar.Size != 3 {
+		t.Fatal("should bind", err)
+	}
+	_, err = m.BindNew(newMultipartRequest(nil, testFile{"avatar", "a.png", ""}), nil)
+	if err == nil || err.Error() != "avatar is empty" {
+		t.Fatal("should not bind empty file", err)
+	}
+
+	v, err = m.FasthttpBindNew(newFasthttpMultipartCtx(nil, testFile{"avatar", "a.png", "png"}), nil)
+	if err != nil || v.(*uploadParams).Avatar.Size != 3 {
+		t.Fatal("should bind", err)
+	}
+	_, err = m.FasthttpBindNew(newFasthttpMultipartCtx(nil, testFile{"avatar", "a.png", ""}), nil)
+	if err == nil || err.Error() != "avatar is empty" {
+		t.Fatal("should not bind empty file", err)
+	}
+
+	type badParams struct {
+		Name string `param:"in(formData),nonempty"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-file field")
+	}
+}
+
+func TestFileSizeAndType(t *testing.T) {
+	type avatarParams struct {
+		Avatar multipart.FileHeader `param:"in(formData),maxsize(4b),accept(image/png|application/*)"`
+		Photo  multipart.FileHeader `param:"in(formData),accept(image/png|image/jpeg)"`
+		Doc    multipart.FileHeader `param:"in(formData),maxsize(1KB)" err:"the doc is invalid"`
+	}
+	m, err := NewParamsAPI(new(avatarParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the uploaded files are `application/octet-stream`
+	if _, err = m.BindNew(newMultipartRequest(nil, testFile{"avatar", "a.png", "png"}), nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(newMultipartRequest(nil, testFile{"avatar", "a.png", "large"}), nil)
+	if err == nil || err.Error() != "avatar must be at most 4b" {
+		t.Fatal("should fail for the large file", err)
+	}
+	_, err = m.FasthttpBindNew(newFasthttpMultipartCtx(nil, testFile{"photo", "a.png", "png"}), nil)
+	if err == nil || err.Error() != "photo must have the content type `image/png|image/jpeg`" {
+		t.Fatal("should fail for the unaccepted content type", err)
+	}
+	_, err = m.BindNew(newMultipartRequest(nil, testFile{"doc", "a.txt", strings.Repeat("x", 1025)}), nil)
+	if err == nil || err.Error() != "the doc is invalid" {
+		t.Fatal("should honor the `err` tag", err)
+	}
+
+	type badParams struct {
+		Name string `param:"in(formData),maxsize(2mb)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-file field")
+	}
+	type badSizeParams struct {
+		Avatar multipart.FileHeader `param:"in(formData),maxsize(2tb)"`
+	}
+	if _, err = NewParamsAPI(new(badSizeParams), nil, nil); err == nil {
+		t.Fatal("should fail for the invalid size")
+	}
+}
+
+func TestBindFieldsWithOptions(t *testing.T) {
+	type optionsUploadParams struct {
+		Title  string      `param:"in(formData),required"`
+		Upload FileHandler `param:"in(formData)"`
+	}
+	m, err := NewParamsAPI(new(optionsUploadParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.SetMaxMemory(4)
+	p, fields := m.NewReceiver()
+	if err = m.BindFields(fields, newMultipartRequest(map[string]string{"title": "photos"}), nil); err == nil {
+		t.Fatal("should fail for exceeding the registered max memory")
+	}
+	err = m.BindFieldsWithOptions(fields, newMultipartRequest(map[string]string{"title": "photos"}), nil, BindOptions{MaxMemory: 1 << 10})
+	if err != nil {
+		t.Fatal("should override the max memory", err)
+	}
+	if title := p.(*optionsUploadParams).Title; title != "photos" {
+		t.Fatal("wrong value", title)
+	}
+}
+
+func TestMultipleFiles(t *testing.T) {
+	type galleryParams struct {
+		Photos []*multipart.FileHeader `param:"in(formData),required,nonempty"`
+		Thumbs []multipart.FileHeader  `param:"in(formData)"`
+	}
+	m, err := NewParamsAPI(new(galleryParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []testFile{{"photos", "a.png", "aaa"}, {"photos", "b.png", "bb"}, {"thumbs", "a.png", "a"}}
+	v, err := m.BindNew(newMultipartRequest(nil, files...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*galleryParams)
+	if len(p.Photos) != 2 || p.Photos[1].Filename != "b.png" || len(p.Thumbs) != 1 || p.Thumbs[0].Size != 1 {
+		t.Fatal("wrong value", p.Photos, p.Thumbs)
+	}
+	v, err = m.FasthttpBindNew(newFasthttpMultipartCtx(nil, files...), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p = v.(*galleryParams); len(p.Photos) != 2 || len(p.Thumbs) != 1 {
+		t.Fatal("wrong value", p.Photos, p.Thumbs)
+	}
+
+	if _, err = m.BindNew(newMultipartRequest(nil, testFile{"thumbs", "a.png", "a"}), nil); err == nil {
+		t.Fatal("should require at least one file")
+	}
+	if _, err = m.BindNew(newMultipartRequest(nil, testFile{"photos", "a.png", "a"}, testFile{"photos", "b.png", ""}), nil); err == nil {
+		t.Fatal("should validate each file")
+	}
+}
+
+func TestMaxTotalMB(t *testing.T) {
+	type albumParams struct {
+		Photos []*multipart.FileHeader          `param:"in(formData),maxtotalmb(1)"`
+		All    map[string]*multipart.FileHeader `param:"in(formData),maxtotalmb(1)"`
+	}
+	m, err := NewParamsAPI(new(albumParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	half := strings.Repeat("x", 600<<10)
+	if _, err = m.BindNew(newMultipartRequest(nil, testFile{"photos", "a.png", half}, testFile{"cover", "b.png", "b"}), nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.BindNew(newMultipartRequest(nil, testFile{"photos", "a.png", half}, testFile{"photos", "b.png", half}), nil)
+	if err == nil || err.Error() != "photos must be at most 1MB in total" {
+		t.Fatal("should fail for exceeding the total size", err)
+	}
+	_, err = m.BindNew(newMultipartRequest(nil, testFile{"photos", "a.png", half}, testFile{"cover", "b.png", half}), nil)
+	if err == nil || err.Error() != "all must be at most 1MB in total" {
+		t.Fatal("should fail for exceeding the total size of all file fields", err)
+	}
+
+	type badParams struct {
+		Avatar multipart.FileHeader `param:"in(formData),maxtotalmb(1)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for the single file field")
+	}
+}
+
+func TestFileHandler(t *testing.T) {
+	type streamUploadParams struct {
+		Title  string      `param:"in(formData),required"`
+		Upload FileHandler `param:"in(formData),required"`
+	}
+	m, err := NewParamsAPI(new(streamUploadParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	p := &streamUploadParams{Upload: func(fh *multipart.FileHeader, r io.Reader) error {
+		b, err := ioutil.ReadAll(r)
+		got = append(got, fh.Filename+":"+string(b))
+		return err
+	}}
+	req := newMultipartRequest(map[string]string{"title": "photos"}, testFile{"upload", "a.png", "aaa"}, testFile{"upload", "b.png", "bb"}, testFile{"other", "c.png", "c"})
+	if err = m.BindAt(p, req, nil); err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "photos" || strings.Join(got, ",") != "a.png:aaa,b.png:bb" {
+		t.Fatal("wrong value", p.Title, got)
+	}
+
+	got = nil
+	if err = m.FasthttpBindAt(p, newFasthttpMultipartCtx(map[string]string{"title": "photos"}, testFile{"upload", "a.png", "aaa"}), nil); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "a.png:aaa" {
+		t.Fatal("wrong value", got)
+	}
+
+	if err = m.BindAt(p, newMultipartRequest(map[string]string{"title": "photos"}), nil); err == nil {
+		t.Fatal("should fail for missing file")
+	}
+	p.Upload = func(*multipart.FileHeader, io.Reader) error { return errors.New("storage unavailable") }
+	err = m.BindAt(p, newMultipartRequest(map[string]string{"title": "photos"}, testFile{"upload", "a.png", "aaa"}), nil)
+	if err == nil || !strings.Contains(err.Error(), "storage unavailable") {
+		t.Fatal("should return the handler error", err)
+	}
+
+	type mixedUploadParams struct {
+		Avatar multipart.FileHeader `param:"in(formData)"`
+		Upload FileHandler          `param:"in(formData)"`
+	}
+	if _, err = NewParamsAPI(new(mixedUploadParams), nil, nil); err == nil {
+		t.Fatal("should fail for the buffered file param with the file handler")
+	}
+}
+
+func TestStrictTags(t *testing.T) {
+	type typoParams struct {
+		Name string `param:"in(query),requred,desc(x)"`
+	}
+	if _, err := NewParamsAPI(new(typoParams), nil, nil); err != nil {
+		t.Fatal("lenient mode should ignore unknown tag keys", err)
+	}
+	_, err := NewParamsAPI(new(typoParams), nil, nil, StrictTags())
+	if e, ok := err.(*Error); !ok || e.Param != "Name" || e.Reason != "unknown `param` tag key `requred`" {
+		t.Fatal("strict mode should fail", err)
+	}
+
+	type goodParams struct {
+		Name string `param:"in(query),required,len(1:3),desc(x)" regexp:"^a" err:"bad name"`
+	}
+	if _, err = NewParamsAPI(new(goodParams), nil, nil, StrictTags()); err != nil {
+		t.Fatal("should register", err)
+	}
+}
+
+func TestHostAndScheme(t *testing.T) {
+	type originParams struct {
+		Host   string `param:"in(host)"`
+		Scheme string `param:"in(scheme)"`
+	}
+	m, err := NewParamsAPI(new(originParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "http://example.com:8080/a", nil)
+	v, err := m.BindNew(req, nil)
+	if p := v.(*originParams); err != nil || p.Host != "example.com:8080" || p.Scheme != "http" {
+		t.Fatal("wrong value", p, err)
+	}
+	req.Header.Set("X-Forwarded-Proto", "HTTPS, http")
+	v, err = m.BindNew(req, nil)
+	if p := v.(*originParams); err != nil || p.Scheme != "https" {
+		t.Fatal("wrong value", p, err)
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/a")
+	ctx.Request.Header.SetHost("example.org")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if p := v.(*originParams); err != nil || p.Host != "example.org" || p.Scheme != "http" {
+		t.Fatal("wrong value", p, err)
+	}
+	ctx.Request.Header.Set("X-Forwarded-Proto", "https")
+	v, err = m.FasthttpBindNew(ctx, nil)
+	if p := v.(*originParams); err != nil || p.Scheme != "https" {
+		t.Fatal("wrong value", p, err)
+	}
+
+	type badParams struct {
+		Host int `param:"in(host)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-string field")
+	}
+}
+
+func TestFileMap(t *testing.T) {
+	type dynamicUploadParams struct {
+		Title string                           `param:"in(formData)"`
+		Files map[string]*multipart.FileHeader `param:"in(formData),required"`
+	}
+	m, err := NewParamsAPI(new(dynamicUploadParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]string{"title": "docs"}
+	files := []testFile{{"resume", "cv.pdf", "pdf"}, {"photo", "me.png", "png!"}}
+	check := func(v interface{}, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := v.(*dynamicUploadParams)
+		if p.Title != "docs" || len(p.Files) != 2 {
+			t.Fatal("wrong value", p)
+		}
+		if fh := p.Files["resume"]; fh == nil || fh.Filename != "cv.pdf" || fh.Size != 3 {
+			t.Fatal("wrong file", fh)
+		}
+		if fh := p.Files["photo"]; fh == nil || fh.Filename != "me.png" || fh.Size != 4 {
+			t.Fatal("wrong file", fh)
+		}
+	}
+	check(m.BindNew(newMultipartRequest(values, files...), nil))
+	check(m.FasthttpBindNew(newFasthttpMultipartCtx(values, files...), nil))
+
+	if _, err = m.BindNew(newMultipartRequest(values), nil); err == nil {
+		t.Fatal("should miss files")
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	type timeParams struct {
+		Since   time.Time   `param:"in(query),nonzero"`
+		Day     time.Time   `param:"in(query),time(2006-01-02)"`
+		Days    []time.Time `param:"in(query),time(2006-01-02)"`
+		Created time.Time   `param:"in(header),name(X-Created),time(Mon, 02 Jan 2006)"`
+	}
+	m, err := NewParamsAPI(new(timeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/?since=2016-12-20T08:00:00Z&day=2016-12-20&days=2016-12-01&days=2016-12-31", nil)
+	req.Header.Set("X-Created", "Tue, 20 Dec 2016")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*timeParams)
+	if !p.Since.Equal(time.Date(2016, 12, 20, 8, 0, 0, 0, time.UTC)) {
+		t.Fatal("wrong value", p.Since)
+	}
+	if !p.Day.Equal(time.Date(2016, 12, 20, 0, 0, 0, 0, time.UTC)) || !p.Created.Equal(p.Day) {
+		t.Fatal("wrong value", p.Day, p.Created)
+	}
+	if len(p.Days) != 2 || p.Days[1].Day() != 31 {
+		t.Fatal("wrong value", p.Days)
+	}
+
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?since=2016-12-20T08:00:00Z&day=20/12/2016", nil), nil)
+	if e, ok := err.(*Error); !ok || e.Param != "day" || !strings.Contains(e.Reason, `"20/12/2016"`) {
+		t.Fatal("should not bind", err)
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?day=2016-12-20", nil), nil)
+	if err == nil || err.Error() != "since not set" {
+		t.Fatal("zero time should not validate", err)
+	}
+
+	type badParams struct {
+		Day string `param:"in(query),time(2006-01-02)"`
+	}
+	if _, err = NewParamsAPI(new(badParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-time field")
+	}
+}
+
+func makeWhitespaceVisible(s string) string {
+	s = strings.Replace(s, "\t", "\\t", -1)
+	s = strings.Replace(s, "\r\n", "\\r\\n", -1)
+	s = strings.Replace(s, "\r", "\\r", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+	return s
+}
+func isZero(v reflect.Value) bool {
+	return v.Interface() == reflect.Zero(v.Type()).Interface()
+}
+
+func toString(v reflect.Value) (string, bool) {
+	s, ok := v.Interface().(string)
+	return s, ok
+}
+
+func TestIndexedSlice(t *testing.T) {
+	type indexedParams struct {
+		Items []int `param:"in(query),name(items)"`
+	}
+	m, err := NewParamsAPI(new(indexedParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?items[2]=3&items[0]=1", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*indexedParams); !reflect.DeepEqual(p.Items, []int{1, 0, 3}) {
+		t.Fatalf("gaps should be zero values: %#v", p.Items)
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/?items[5000]=1", nil), nil); err == nil {
+		t.Fatal("should fail for the too large index")
+	}
+
+	strict, err := NewParamsAPI(new(indexedParams), nil, nil, StrictSliceIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = strict.BindNew(httptest.NewRequest("GET", "/?items[2]=3&items[0]=1", nil), nil); err == nil {
+		t.Fatal("should fail for the gapped indexes")
+	}
+	v, err = strict.BindNew(httptest.NewRequest("GET", "/?items[1]=2&items[0]=1", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*indexedParams); !reflect.DeepEqual(p.Items, []int{1, 2}) {
+		t.Fatalf("wrong value: %#v", p.Items)
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	type authParams struct {
+		Auth Authorization `param:"in(auth),required"`
+	}
+	m, err := NewParamsAPI(new(authParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("user", "pa:ss")
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth := v.(*authParams).Auth; auth.Scheme != "Basic" || auth.User != "user" || auth.Password != "pa:ss" {
+		t.Fatalf("wrong basic auth: %#v", auth)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer abc.def")
+	v, err = m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth := v.(*authParams).Auth; auth.Scheme != "Bearer" || auth.Credentials != "abc.def" || auth.User != "" {
+		t.Fatalf("wrong bearer auth: %#v", auth)
+	}
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Basic !!!")
+	if _, err = m.BindNew(req, nil); err == nil {
+		t.Fatal("should fail for the invalid basic credentials")
+	}
+	if _, err = m.BindNew(httptest.NewRequest("GET", "/", nil), nil); err == nil {
+		t.Fatal("should fail for the missing required auth")
+	}
+
+	type badAuthParams struct {
+		Auth string `param:"in(auth)"`
+	}
+	if _, err = NewParamsAPI(new(badAuthParams), nil, nil); err == nil {
+		t.Fatal("should fail for the non-Authorization field")
+	}
+}
+
+func TestParamInfos(t *testing.T) {
+	type infoParams struct {
+		ID     int                  `param:"in(path),name(id),desc(the user id),range(1:100)"`
+		Name   string               `param:"in(query),len(2:8)" regexp:"^[a-z]+$"`
+		Avatar multipart.FileHeader `param:"in(formData)"`
+	}
+	m, err := NewParamsAPI(new(infoParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos := m.ParamInfos()
+	if len(infos) != 3 {
+		t.Fatal("wrong number of params", len(infos))
+	}
+	id := infos[0]
+	if id.Name != "id" || id.In != "path" || id.Type != "int" || !id.Required || id.Description != "the user id" || id.Range != "1:100" {
+		t.Fatalf("wrong id info: %#v", id)
+	}
+	name := infos[1]
+	if name.Required || name.Len != "2:8" || name.Regexp != "^[a-z]+$" || name.IsFile {
+		t.Fatalf("wrong name info: %#v", name)
+	}
+	if !infos[2].IsFile {
+		t.Fatalf("wrong avatar info: %#v", infos[2])
+	}
+	infos[1].Tags["len"] = "0:1"
+	if m.ParamInfos()[1].Tags["len"] != "2:8" {
+		t.Fatal("the tags should be a copy")
+	}
+}
+
+func TestCharSet(t *testing.T) {
+	type charSetParams struct {
+		User    string `param:"in(query),charset(a-zA-Z0-9_)"`
+		Comment string `param:"in(query),denychars(<>&)"`
+		Dash    string `param:"in(query),charset(a-c-)"`
+		Word    string `param:"in(query),charset(α-ω)"`
+	}
+	m, err := NewParamsAPI(new(charSetParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(query string) error {
+		_, err := m.BindNew(httptest.NewRequest("GET", "/?"+query, nil), nil)
+		return err
+	}
+	for _, query := range []string{"user=Abc_09", "user=", "comment=hi+there!", "dash=a-c", "word=αβω"} {
+		if err = bind(query); err != nil {
+			t.Fatal(query, err)
+		}
+	}
+	for _, query := range []string{"user=a-b", "user=ab%C3%A9", "comment=%3Cb%3E", "comment=a%26b", "dash=d", "word=%CE%91"} {
+		if err = bind(query); err == nil {
+			t.Fatal("should not bind", query)
+		}
+	}
+
+	type badCharSetParams struct {
+		User string `param:"in(query),charset(z-a)"`
+	}
+	if _, err = NewParamsAPI(new(badCharSetParams), nil, nil); err == nil {
+		t.Fatal("should fail for the reversed range")
+	}
+	type intCharSetParams struct {
+		ID int `param:"in(query),charset(0-9)"`
+	}
+	if _, err = NewParamsAPI(new(intCharSetParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-string field")
+	}
+}
+
+func TestUnexportedField(t *testing.T) {
+	type unexportedParams struct {
+		Name  string `param:"in(query)"`
+		token string `param:"in(header)"`
+	}
+	_, err := NewParamsAPI(new(unexportedParams), nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "must be exported") {
+		t.Fatal("should fail for the unexported field", err)
+	}
+
+	type ignoredParams struct {
+		Name  string `param:"in(query)"`
+		token string `param:"-"`
+		cache string
+	}
+	if _, err = NewParamsAPI(new(ignoredParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBindBatch(t *testing.T) {
+	type batchItem struct {
+		Name  string `param:"in(query),required,len(3:)" json:"name"`
+		Count int    `param:"in(query),range(1:10)" json:"count"`
+	}
+	if err := Register(new(batchItem), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`[{"name":"apple","count":1},{"name":"go","count":2},{"name":"pear","count":3}]`))
+	items, errs := BindBatch(new(batchItem), req)
+	if len(items) != 3 {
+		t.Fatal("should decode three items", items)
+	}
+	if name := items[2].Interface().(*batchItem).Name; name != "pear" {
+		t.Fatal("wrong value:", name)
+	}
+	if len(errs) != 1 {
+		t.Fatal("should fail for the second item only", errs)
+	}
+	e, ok := errs[0].(*ValidationError)
+	if !ok || e.Field() != "[1].name" {
+		t.Fatal("should point out the index", errs[0])
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"apple"}`))
+	if _, errs = BindBatch(new(batchItem), req); len(errs) != 1 {
+		t.Fatal("should fail for a non-array body")
+	}
+}
+
+func TestBindMulti(t *testing.T) {
+	type multiPaging struct {
+		Page int `param:"in(query),range(1:)"`
+		Size int `param:"in(query),default(10)"`
+	}
+	type multiFilters struct {
+		ID     int    `param:"in(path),name(id)"`
+		Status string `param:"in(query),oneof(on|off)"`
+	}
+	type multiBody struct {
+		Body map[string]string `param:"in(body)"`
+	}
+	type multiBody2 struct {
+		Body []string `param:"in(body)"`
+	}
+	for _, structPointer := range []interface{}{new(multiPaging), new(multiFilters), new(multiBody), new(multiBody2)} {
+		if err := Register(structPointer, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	paging, filters, body := new(multiPaging), new(multiFilters), new(multiBody)
+	req := httptest.NewRequest("POST", "/items/3?page=2&status=on", strings.NewReader(`{"a":"b"}`))
+	if err := BindMulti(req, Map{"id": "3"}, paging, filters, body); err != nil {
+		t.Fatal(err)
+	}
+	if paging.Page != 2 || paging.Size != 10 || filters.ID != 3 || filters.Status != "on" || body.Body["a"] != "b" {
+		t.Fatalf("wrong value: %#v %#v %#v", paging, filters, body)
+	}
+
+	req = httptest.NewRequest("GET", "/items/3?page=0&status=x", nil)
+	err := BindMulti(req, Map{"id": "3"}, new(multiPaging), new(multiFilters))
+	if errs, ok := err.(MultiError); !ok || len(errs) != 2 {
+		t.Fatal("should aggregate the errors of each struct", err)
+	}
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	if err = BindMulti(req, nil, new(multiBody), new(multiBody2)); err == nil {
+		t.Fatal("should fail for more than one body")
+	}
+}
+
+func TestEqConstant(t *testing.T) {
+	type termsParams struct {
+		AcceptTerms bool    `param:"in(formData),eq(true)"`
+		Role        string  `param:"in(formData),ne(admin)"`
+		Version     int     `param:"in(formData),eq(2)"`
+		Ratio       float64 `param:"in(formData),ne(0.5)"`
+	}
+	m, err := NewParamsAPI(new(termsParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(form string) error {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		_, err := m.BindNew(req, nil)
+		return err
+	}
+	if err = bind("accept_terms=on&role=user&version=2&ratio=1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, form := range []string{
+		"role=user&version=2",
+		"accept_terms=false&role=user&version=2",
+		"accept_terms=true&role=admin&version=2",
+		"accept_terms=true&role=user&version=3",
+		"accept_terms=true&role=user&version=2&ratio=0.5",
+	} {
+		if err = bind(form); err == nil {
+			t.Fatal("should not bind", form)
+		}
+	}
+	if err = bind("accept_terms=true&role=admin&version=2"); err == nil || err.Error() != "role must not equal admin" {
+		t.Fatal("wrong error", err)
+	}
+
+	type badEqParams struct {
+		Version int `param:"in(query),eq(x)"`
+	}
+	if _, err = NewParamsAPI(new(badEqParams), nil, nil); err == nil {
+		t.Fatal("should fail for the invalid constant")
+	}
+	type sliceEqParams struct {
+		Tags []string `param:"in(query),eq(a)"`
+	}
+	if _, err = NewParamsAPI(new(sliceEqParams), nil, nil); err == nil {
+		t.Fatal("should fail for the slice field")
+	}
+}
+
+func TestStructuredValidationError(t *testing.T) {
+	type structuredParams struct {
+		P    string `param:"in(query),name(p),len(2:8)"`
+		Page int    `param:"in(query),range(1:10)"`
+		Code string `param:"in(query),nonzero" regexp:"^[a-z]+$"`
+	}
+	m, err := NewParamsAPI(new(structuredParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for query, want := range map[string][4]string{
+		"p=a&page=1&code=x":   {"p", "len", "2:8", "too_short"},
+		"p=ab&page=11&code=x": {"page", "range", "1:10", "too_big"},
+		"p=ab&page=1&code=X":  {"code", "regexp", "^[a-z]+$", "not_match"},
+		"p=ab&page=1":         {"code", "nonzero", "", "not_set"},
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", "/?"+query, nil), nil)
+		e, ok := err.(*ValidationError)
+		if !ok {
+			t.Fatal("should be a validation error", query, err)
+		}
+		if got := [4]string{e.Field(), e.Rule(), e.Constraint(), string(e.Code())}; got != want {
+			t.Fatal("wrong structured error", query, got)
+		}
+	}
+	_, err = m.BindNew(httptest.NewRequest("GET", "/?p=a&page=1&code=x", nil), nil)
+	b, err := json.Marshal(err)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"field":"p","rule":"len","constraint":"2:8","code":"too_short","message":"p too short"}` {
+		t.Fatal("wrong JSON", string(b))
+	}
+}
+
+func TestReadonly(t *testing.T) {
+	type readonlyParams struct {
+		Name      string `param:"in(formData)"`
+		CreatedAt string `param:"in(formData),readonly"`
+		Owner     string `param:"in(header),name(X-Owner),readonly"`
+	}
+	m, err := NewParamsAPI(new(readonlyParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(form string, header string) (*readonlyParams, error) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if header != "" {
+			req.Header.Set("X-Owner", header)
+		}
+		params := &readonlyParams{CreatedAt: "2016-01-01"}
+		return params, m.BindAt(params, req, nil)
+	}
+	params, err := bind("name=a", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Name != "a" || params.CreatedAt != "2016-01-01" {
+		t.Fatalf("the read-only field should be untouched: %#v", params)
+	}
+	if _, err = bind("name=a&created_at=2020-01-01", ""); err == nil {
+		t.Fatal("should fail for the supplied read-only param")
+	}
+	if _, err = bind("name=a", "henry"); err == nil {
+		t.Fatal("should fail for the supplied read-only header")
+	}
+
+	type badReadonlyParams struct {
+		ID int `param:"in(query),readonly,required"`
+	}
+	if _, err = NewParamsAPI(new(badReadonlyParams), nil, nil); err == nil {
+		t.Fatal("should fail for the required read-only param")
+	}
+}
+
+func TestURLValuesLimits(t *testing.T) {
+	type capturedParams struct {
+		All url.Values `param:"in(query),maxkeys(2),maxvalues(3)"`
+	}
+	m, err := NewParamsAPI(new(capturedParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?a=1&b=2&b=3", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := v.(*capturedParams); !reflect.DeepEqual(p.All, url.Values{"a": {"1"}, "b": {"2", "3"}}) {
+		t.Fatalf("wrong value: %#v", p.All)
+	}
+	for query, rule := range map[string]string{
+		"a=1&b=2&c=3":     "maxkeys",
+		"a=1&b=2&b=3&b=4": "maxvalues",
+	} {
+		_, err = m.BindNew(httptest.NewRequest("GET", "/?"+query, nil), nil)
+		if e, ok := err.(*ValidationError); !ok || e.Rule() != rule {
+			t.Fatal("should fail for the limit", query, err)
+		}
+	}
+
+	type badCapturedParams struct {
+		All string `param:"in(query),maxkeys(2)"`
+	}
+	if _, err = NewParamsAPI(new(badCapturedParams), nil, nil); err == nil {
+		t.Fatal("should fail for non-map field")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	type normalizeParams struct {
+		Email string   `param:"in(formData),trim,lower,len(3:20)" regexp:"^[a-z@.]+$"`
+		Codes []string `param:"in(formData),trim,upper,oneof(AB|CD)"`
+	}
+	m, err := NewParamsAPI(new(normalizeParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(form string) (*normalizeParams, error) {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		v, err := m.BindNew(req, nil)
+		if err != nil {
+			return nil, err
+		}
+		return v.(*normalizeParams), nil
+	}
+	p, err := bind("email=+Henry@Example.COM+&codes=ab&codes=+cd+")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Email != "henry@example.com" || !reflect.DeepEqual(p.Codes, []string{"AB", "CD"}) {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	if _, err = bind("email=+Henry+1@x.com"); err == nil {
+		t.Fatal("should validate the normalized value")
+	}
+
+	type badNormalizeParams struct {
+		Name string `param:"in(query),lower,upper"`
+	}
+	if _, err = NewParamsAPI(new(badNormalizeParams), nil, nil); err == nil {
+		t.Fatal("should fail for both lower and upper")
+	}
+}
+
+type EmbeddedPaging struct {
+	Page int `param:"in(query),default(1)"`
+}
+
+type EmbeddedLabel string
+
+type EmbeddedRecursive struct {
+	*EmbeddedRecursive
+	Name string `param:"in(query)"`
+}
+
+func TestEmbeddedFields(t *testing.T) {
+	type embeddedParams struct {
+		*EmbeddedPaging
+		EmbeddedLabel `param:"in(query),name(label)"`
+		Name          string `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(embeddedParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Number() != 3 {
+		t.Fatal("wrong number of params", m.Number())
+	}
+	v, err := m.BindNew(httptest.NewRequest("GET", "/?page=3&label=x&name=y", nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := v.(*embeddedParams)
+	if p.EmbeddedPaging == nil || p.Page != 3 || p.EmbeddedLabel != "x" || p.Name != "y" {
+		t.Fatalf("wrong value: %#v", p)
+	}
+	params := new(embeddedParams)
+	if err = m.BindAt(params, httptest.NewRequest("GET", "/", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if params.EmbeddedPaging == nil || params.Page != 1 {
+		t.Fatal("the embedded struct pointer should be allocated")
+	}
+
+	// the registration leaves the caller's struct untouched
+	raw := new(embeddedParams)
+	if m, err = NewParamsAPI(raw, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if raw.EmbeddedPaging != nil {
+		t.Fatal("the registration should not allocate the embedded struct pointer")
+	}
+	if _, err = m.RawBind(httptest.NewRequest("GET", "/?page=5", nil), nil); err != nil {
+		t.Fatal(err)
+	}
+	if raw.EmbeddedPaging == nil || raw.Page != 5 {
+		t.Fatal("the embedded struct pointer should be allocated when binding")
+	}
+
+	type untaggedParams struct {
+		EmbeddedLabel
+		Name string `param:"in(query)"`
+	}
+	if m, err = NewParamsAPI(new(untaggedParams), nil, nil); err != nil || m.Number() != 1 {
+		t.Fatal("the untagged anonymous non-struct field should be ignored", err)
+	}
+	if _, err = NewParamsAPI(new(EmbeddedRecursive), nil, nil); err == nil {
+		t.Fatal("should fail for the recursive embedded struct")
+	}
+}
+
+func TestBCP47(t *testing.T) {
+	type langParams struct {
+		Lang string `param:"in(query),bcp47"`
+	}
+	m, err := NewParamsAPI(new(langParams), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bind := func(lang string) error {
+		_, err := m.BindNew(httptest.NewRequest("GET", "/?lang="+url.QueryEscape(lang), nil), nil)
+		return err
+	}
+	for _, lang := range []string{"en", "en-US", "zh-Hant-TW", "es-419", "sl-rozaj-biske", "de-CH-1901", "zh-yue-HK", "en-a-bbb-x-a-ccc", "x-whatever", "EN-us"} {
+		if err = bind(lang); err != nil {
+			t.Fatal(lang, err)
+		}
+	}
+	for _, lang := range []string{"e", "en-", "-en", "en--US", "en_US", "toolonglang", "en-US-a", "en-x", "12-US", "é"} {
+		if err = bind(lang); err == nil {
+			t.Fatal("should not bind", lang)
+		}
+	}
+	if err = bind("en_US"); err.Error() != "lang is not a valid BCP 47 language tag" {
+		t.Fatal("wrong error", err)
+	}
+}
+
+type BenchEmbedded struct {
+	Page int `param:"in(query)"`
+	Size int `param:"in(query)"`
+}
+
+type BenchEmbeddedPtr struct {
+	Sort  string `param:"in(query)"`
+	Order string `param:"in(query)"`
+}
+
+// benchParams has 10 params, including the embedded struct and struct pointer ones.
+type benchParams struct {
+	BenchEmbedded
+	*BenchEmbeddedPtr
+	A string  `param:"in(query)"`
+	B string  `param:"in(query)"`
+	C int     `param:"in(query)"`
+	D int     `param:"in(query)"`
+	E bool    `param:"in(query)"`
+	F float64 `param:"in(query)"`
+}
+
+type BenchDeep4 struct {
+	H string `param:"in(query)"`
+	I string `param:"in(query)"`
+	J string `param:"in(query)"`
+}
+
+type BenchDeep3 struct {
+	*BenchDeep4
+	F string `param:"in(query)"`
+	G string `param:"in(query)"`
+}
+
+type BenchDeep2 struct {
+	BenchDeep3
+	D string `param:"in(query)"`
+	E string `param:"in(query)"`
+}
+
+// benchDeepParams has 10 params, nested in the embedded structs up to 4 levels deep.
+type benchDeepParams struct {
+	*BenchDeep2
+	A string `param:"in(query)"`
+	B string `param:"in(query)"`
+	C string `param:"in(query)"`
+}
+
+// walkFields is the per-param walk from the root struct, as a reference for the cached fields.
+func walkFields(m *ParamsAPI, structElem reflect.Value) []reflect.Value {
+	fields := make([]reflect.Value, len(m.params))
+	for i, param := range m.params {
+		value := structElem
+		for _, index := range param.indexPath {
+			if value.Kind() == reflect.Ptr {
+				if value.IsNil() {
+					value.Set(reflect.New(value.Type().Elem()))
+				}
+				value = value.Elem()
+			}
+			value = value.Field(index)
+		}
+		fields[i] = value
+	}
+	return fields
+}
+
+func TestFieldsForBinding(t *testing.T) {
+	for _, structPointer := range []interface{}{new(benchParams), new(benchDeepParams)} {
+		m, err := NewParamsAPI(structPointer, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, gotFields := m.NewReceiver()
+		want := reflect.New(m.structType)
+		wantFields := walkFields(m, want.Elem())
+		for i, fields := range [][]reflect.Value{gotFields, wantFields} {
+			for j, field := range fields {
+				switch field.Kind() {
+				case reflect.String:
+					field.SetString(strconv.Itoa(j))
+				case reflect.Int:
+					field.SetInt(int64(j))
+				case reflect.Bool:
+					field.SetBool(true)
+				case reflect.Float64:
+					field.SetFloat(float64(j))
+				default:
+					t.Fatal("unexpected field", i, m.params[j].name)
+				}
+			}
+		}
+		if !reflect.DeepEqual(got, want.Interface()) {
+			t.Fatalf("wrong fields %#v", got)
+		}
+	}
+}
+
+func BenchmarkNewReceiver(b *testing.B) {
+	for _, c := range []struct {
+		name          string
+		structPointer interface{}
+	}{
+		{"Embedded", new(benchParams)},
+		{"Deep", new(benchDeepParams)},
+	} {
+		m, err := NewParamsAPI(c.structPointer, nil, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(c.name+"/Walk", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				walkFields(m, reflect.New(m.structType).Elem())
+			}
+		})
+		b.Run(c.name+"/Cached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				m.NewReceiver()
+			}
+		})
+	}
 }
@@ -0,0 +1,212 @@
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed SemVer 2.0 version, the build metadata is ignored.
+type semver struct {
+	core       [3]uint64
+	prerelease []string
+}
+
+// parseSemver parses s as a SemVer 2.0 version, e.g. `1.2.3-beta.1+build.5`.
+func parseSemver(s string) (v semver, ok bool) {
+	if i := strings.IndexByte(s, '+'); i != -1 {
+		if !validIdentifiers(s[i+1:], false) {
+			return v, false
+		}
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i != -1 {
+		if !validIdentifiers(s[i+1:], true) {
+			return v, false
+		}
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		if !isNumeric(p) || (len(p) > 1 && p[0] == '0') {
+			return v, false
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// validIdentifiers tests the dot separated identifiers of the pre-release or build metadata,
+// the numeric pre-release identifiers can not have leading zeros.
+func validIdentifiers(s string, prerelease bool) bool {
+	for _, id := range strings.Split(s, ".") {
+		if len(id) == 0 {
+			return false
+		}
+		for i := 0; i < len(id); i++ {
+			c := id[i]
+			if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-') {
+				return false
+			}
+		}
+		if prerelease && len(id) > 1 && id[0] == '0' && isNumeric(id) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumeric(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// compare returns -1, 0 or 1 as v is lower than, equal to or higher than w, by the SemVer precedence.
+func (v semver) compare(w semver) int {
+	for i := range v.core {
+		if v.core[i] != w.core[i] {
+			if v.core[i] < w.core[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a pre-release version has lower precedence than the normal version
+	switch {
+	case len(v.prerelease) == 0 && len(w.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(w.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(w.prerelease); i++ {
+		a, b := v.prerelease[i], w.prerelease[i]
+		if a == b {
+			continue
+		}
+		aNum, bNum := isNumeric(a), isNumeric(b)
+		switch {
+		case aNum && bNum:
+			x, _ := strconv.ParseUint(a, 10, 64)
+			y, _ := strconv.ParseUint(b, 10, 64)
+			if x < y {
+				return -1
+			}
+			return 1
+		case aNum:
+			return -1
+		case bNum:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch {
+	case len(v.prerelease) < len(w.prerelease):
+		return -1
+	case len(v.prerelease) > len(w.prerelease):
+		return 1
+	}
+	return 0
+}
+
+// semverConstraint is a comparison like `>=1.2.0`.
+type semverConstraint struct {
+	op      string
+	version semver
+	raw     string
+}
+
+// parseSemverConstraints parses the space separated constraints of the `semver` tag,
+// e.g. `>=1.2.0 <2.0.0`, the operators are `=`, `>`, `>=`, `<` and `<=`.
+func parseSemverConstraints(s string) ([]semverConstraint, error) {
+	var constraints []semverConstraint
+	for _, c := range strings.Fields(s) {
+		var op string
+		for _, o := range []string{">=", "<=", ">", "<", "="} {
+			if strings.HasPrefix(c, o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			op, c = "=", "="+c
+		}
+		v, ok := parseSemver(c[len(op):])
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid semver constraint", c)
+		}
+		constraints = append(constraints, semverConstraint{op: op, version: v, raw: c[len(op):]})
+	}
+	return constraints, nil
+}
+
+// validateSemver tests if s is a SemVer 2.0 version satisfying all the constraints,
+// the error reads like "version must be >= 1.2.0".
+func validateSemver(s, constraints, paramName string) error {
+	v, ok := parseSemver(s)
+	if !ok {
+		return newFormatError("semver", paramName)
+	}
+	cs, err := parseSemverConstraints(constraints)
+	if err != nil {
+		return err
+	}
+	for _, c := range cs {
+		n := v.compare(c.version)
+		var satisfied bool
+		switch c.op {
+		case "=":
+			satisfied = n == 0
+		case ">":
+			satisfied = n > 0
+		case ">=":
+			satisfied = n >= 0
+		case "<":
+			satisfied = n < 0
+		case "<=":
+			satisfied = n <= 0
+		}
+		if satisfied {
+			continue
+		}
+		kind := ValidationErrorValueTooBig
+		if n < 0 {
+			kind = ValidationErrorValueTooSmall
+		}
+		return &ValidationError{kind: kind, field: paramName, expect: c.op + " " + c.raw}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build go1.22
+
+// Copyright 2016 HenryLee. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apiware
+
+import "net/http"
+
+// stdMuxKV looks up the path params matched by the Go 1.22 `http.ServeMux` wildcards.
+type stdMuxKV struct {
+	req *http.Request
+}
+
+// Get returns the value of the wildcard, it is not found if the value is empty.
+func (kv stdMuxKV) Get(k string) (string, bool) {
+	v := kv.req.PathValue(k)
+	return v, len(v) > 0
+}
+
+// StdMuxPathParams returns the path params of the request routed by the Go 1.22 `http.ServeMux`,
+// e.g. the `{id}` wildcard of the pattern `GET /users/{id}` is the `id` path param.
+// note: it does not implement `KeysKV`, so the catch-all `map[string]string` path param is not supported.
+func StdMuxPathParams(req *http.Request) KV {
+	return stdMuxKV{req: req}
+}
+
+// BindStdMux binds the net/http request params routed by the Go 1.22 `http.ServeMux` to the structure and validate.
+// note: structPointer must be structure pointer.
+func BindStdMux(structPointer interface{}, req *http.Request) error {
+	return Bind(structPointer, req, StdMuxPathParams(req))
+}
@@ -0,0 +1,44 @@
+//go:build go1.22
+
+package apiware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBindStdMux(t *testing.T) {
+	type stdMuxParams struct {
+		ID   int    `param:"in(path),name(id)"`
+		Name string `param:"in(path)"`
+		Q    string `param:"in(query)"`
+	}
+	if err := Register(new(stdMuxParams), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var params *stdMuxParams
+	var bindErr error
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}/{name}", func(w http.ResponseWriter, r *http.Request) {
+		params = new(stdMuxParams)
+		bindErr = BindStdMux(params, r)
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		params = new(stdMuxParams)
+		bindErr = BindStdMux(params, r)
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7/henry?q=x", nil))
+	if bindErr != nil {
+		t.Fatal(bindErr)
+	}
+	if params.ID != 7 || params.Name != "henry" || params.Q != "x" {
+		t.Fatalf("wrong value: %#v", params)
+	}
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/7", nil))
+	if bindErr == nil {
+		t.Fatal("should fail for the missing path param")
+	}
+}
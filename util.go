@@ -16,9 +16,22 @@ package apiware
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
 	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 func toSnake(s string) string {
@@ -32,6 +45,59 @@ func toSnake(s string) string {
 	return strings.ToLower(buf.String())
 }
 
+// AsIs is a ParamNameFunc that uses the Go field name verbatim.
+func AsIs(fieldName string) string {
+	return fieldName
+}
+
+// ToCamel is a ParamNameFunc that converts the field name to lower camelCase,
+// e.g. "UserName" -> "userName", "UserID" -> "userID", "HTTPServer" -> "httpServer".
+func ToCamel(fieldName string) string {
+	rs := []rune(fieldName)
+	n := 0
+	for n < len(rs) && unicode.IsUpper(rs[n]) {
+		n++
+	}
+	// keep the last capital of a leading acronym as the start of the next word
+	if n > 1 && n < len(rs) && unicode.IsLower(rs[n]) {
+		n--
+	}
+	for i := 0; i < n; i++ {
+		rs[i] = unicode.ToLower(rs[i])
+	}
+	return string(rs)
+}
+
+// ToKebab is a ParamNameFunc that converts the field name to kebab-case,
+// keeping acronyms together, e.g. "UserID" -> "user-id", "HTTPServer" -> "http-server".
+func ToKebab(fieldName string) string {
+	rs := []rune(fieldName)
+	buf := bytes.NewBufferString("")
+	for i, v := range rs {
+		if i > 0 && unicode.IsUpper(v) {
+			prev := rs[i-1]
+			if !unicode.IsUpper(prev) || (i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+				buf.WriteRune('-')
+			}
+		}
+		buf.WriteRune(unicode.ToLower(v))
+	}
+	return buf.String()
+}
+
+// isEndianType tests if t is a fixed-width integer type, its pointer or slice.
+func isEndianType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
 func interfaceToSnake(f interface{}) string {
 	t := reflect.TypeOf(f)
 	for {
@@ -69,14 +135,380 @@ func bodyJONS(dest reflect.Value, body []byte) error {
 	return err
 }
 
+// parseSize parses the size like `2mb`, `512kb`, `1gb` or `1024`(bytes), case-insensitively.
+func parseSize(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range []struct {
+		suffix string
+		size   int64
+	}{{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30}, {"b", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, unit = strings.TrimSuffix(s, u.suffix), u.size
+			break
+		}
+	}
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || i <= 0 {
+		return 0, errors.New("it must be a positive size, e.g. `2mb`")
+	}
+	return i * unit, nil
+}
+
+// acceptMediaType tests if the media type of the contentType is one of the `|` separated list,
+// the list item can be a wildcard like `image/*`.
+func acceptMediaType(contentType, list string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, accept := range strings.Split(list, "|") {
+		accept = strings.ToLower(strings.TrimSpace(accept))
+		if accept == mediaType || strings.HasSuffix(accept, "/*") && strings.HasPrefix(mediaType, accept[:len(accept)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader counts the bytes read from the request body for the `bodysize` params.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// checkJSONDepth scans the JSON tokens of the body,
+// and fails when the objects and arrays are nested deeper than max, no limit if max is 0.
+func checkJSONDepth(body []byte, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	var depth int
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if depth++; depth > max {
+				return fmt.Errorf("JSON nesting depth exceeds %d", max)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
+
+// BodyJSONUseNumber is a BodyDecodeFunc like the default one,
+// but it decodes the numbers into `interface{}` values as `json.Number`,
+// so that large integers keep their precision.
+func BodyJSONUseNumber(dest reflect.Value, body []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dest.Kind() == reflect.Ptr {
+		return dec.Decode(dest.Interface())
+	}
+	return dec.Decode(dest.Addr().Interface())
+}
+
+// contextReader fails the reads once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	io.ReadCloser
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
+
+// BodyXML is a BodyDecodeFunc which decodes the XML body by `encoding/xml`,
+// respecting the `xml` struct tags.
+func BodyXML(dest reflect.Value, body []byte) error {
+	if dest.Kind() == reflect.Ptr {
+		return xml.Unmarshal(body, dest.Interface())
+	}
+	return xml.Unmarshal(body, dest.Addr().Interface())
+}
+
+// KeyValue is a bracketed query param, e.g. `filter[a]=1` is bound as `KeyValue{Key: "a", Value: "1"}`.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+var readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+
+var (
+	urlValuesType  = reflect.TypeOf(url.Values{})
+	keyValuesType  = reflect.TypeOf([]KeyValue{})
+	stringMapType  = reflect.TypeOf(map[string]string{})
+	stringsMapType = reflect.TypeOf(map[string][]string{})
+)
+
+// copyValues returns a copy of the values, so that the bound field does not share them.
+func copyValues(values url.Values) url.Values {
+	c := make(url.Values, len(values))
+	for k, v := range values {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// isKeyValuesType tests if t receives the bracketed query params.
+func isKeyValuesType(t reflect.Type) bool {
+	return t == keyValuesType || t == stringMapType || t == stringsMapType
+}
+
+// setKeyValues assigns the bracketed query params to the `[]KeyValue`, `map[string]string`
+// or `map[string][]string` value, the last value of a key wins for `map[string]string`.
+func setKeyValues(value reflect.Value, kvs []KeyValue) {
+	switch value.Type() {
+	case stringMapType:
+		m := make(map[string]string, len(kvs))
+		for _, kv := range kvs {
+			m[kv.Key] = kv.Value
+		}
+		value.Set(reflect.ValueOf(m))
+	case stringsMapType:
+		m := make(map[string][]string, len(kvs))
+		for _, kv := range kvs {
+			m[kv.Key] = append(m[kv.Key], kv.Value)
+		}
+		value.Set(reflect.ValueOf(m))
+	default:
+		value.Set(reflect.ValueOf(kvs))
+	}
+}
+
+// parseOrderedQuery parses the query string, keeping the order of the params.
+func parseOrderedQuery(query string) []KeyValue {
+	var kvs []KeyValue
+	for _, part := range strings.Split(query, "&") {
+		if len(part) == 0 {
+			continue
+		}
+		k, v := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			k, v = part[:i], part[i+1:]
+		}
+		k, err := url.QueryUnescape(k)
+		if err != nil {
+			continue
+		}
+		v, err = url.QueryUnescape(v)
+		if err != nil {
+			continue
+		}
+		kvs = append(kvs, KeyValue{Key: k, Value: v})
+	}
+	return kvs
+}
+
+// isNoValueArg tests if the query arg is only given in the no-value form, e.g. `?name`, but not `?name=`.
+func isNoValueArg(query, name string) bool {
+	var found bool
+	for _, part := range strings.Split(query, "&") {
+		k, v := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			k, v = part[:i], part[i:]
+		}
+		if k, err := url.QueryUnescape(k); err != nil || k != name {
+			continue
+		}
+		if len(v) > 0 {
+			return false
+		}
+		found = true
+	}
+	return found
+}
+
+// bracketKeyValues picks out the params named like `prefix[key]`, and trims their keys to `key`.
+func bracketKeyValues(kvs []KeyValue, prefix string) []KeyValue {
+	var r []KeyValue
+	for _, kv := range kvs {
+		if key, ok := bracketKey(kv.Key, prefix); ok {
+			r = append(r, KeyValue{Key: key, Value: kv.Value})
+		}
+	}
+	return r
+}
+
+// isIndexableSlice tests if t receives the indexed params like `items[0]`.
+func isIndexableSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t != bytesType && t != keyValuesType && t != sortFieldsType
+}
+
+// bracketIndexes picks out the first values of the params named like `prefix[0]`, by their indexes.
+func bracketIndexes(form map[string][]string, prefix string) map[int]string {
+	var indexed map[int]string
+	for k, vs := range form {
+		key, ok := bracketKey(k, prefix)
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 {
+			continue
+		}
+		if indexed == nil {
+			indexed = make(map[int]string)
+		}
+		indexed[i] = vs[0]
+	}
+	return indexed
+}
+
+// bracketKey returns `key` if s is like `prefix[key]`.
+func bracketKey(s, prefix string) (string, bool) {
+	if len(s) > len(prefix)+2 && strings.HasPrefix(s, prefix) && s[len(prefix)] == '[' && s[len(s)-1] == ']' {
+		return s[len(prefix)+1 : len(s)-1], true
+	}
+	return "", false
+}
+
+// isETagHeader tests if the header carries the ETag values to compare, e.g. `If-Match`.
+func isETagHeader(name string) bool {
+	switch textproto.CanonicalMIMEHeaderKey(name) {
+	case "If-Match", "If-None-Match":
+		return true
+	}
+	return false
+}
+
+// unquoteETags splits the comma separated ETags and strips their quotes,
+// e.g. `"a", W/"b"` is `[]string{"a", "W/b"}`, the weak prefix is kept.
+func unquoteETags(values []string) []string {
+	var etags []string
+	for _, v := range values {
+		for _, etag := range strings.Split(v, ",") {
+			etag = strings.TrimSpace(etag)
+			var weak string
+			if strings.HasPrefix(etag, "W/") {
+				weak, etag = "W/", etag[2:]
+			}
+			if len(etag) >= 2 && etag[0] == '"' && etag[len(etag)-1] == '"' {
+				etag = etag[1 : len(etag)-1]
+			}
+			etags = append(etags, weak+etag)
+		}
+	}
+	return etags
+}
+
+// Authorization is the `in(auth)` param parsed from the `Authorization` header,
+// e.g. `Bearer abc` is `Authorization{Scheme: "Bearer", Credentials: "abc"}`,
+// and the Basic credentials are decoded into User and Password.
+type Authorization struct {
+	Scheme      string // e.g. `Basic` or `Bearer`
+	Credentials string // the raw credentials after the scheme
+	User        string // the user of the Basic credentials
+	Password    string // the password of the Basic credentials
+}
+
+var authorizationType = reflect.TypeOf(Authorization{})
+
+// FileHandler is the `in(formData)` param type which streams each uploaded file of the form field during binding,
+// instead of buffering it into a `multipart.FileHeader`,
+// the handler must be set before binding, and the fh.Size of a streamed file is unknown (zero).
+type FileHandler func(fh *multipart.FileHeader, r io.Reader) error
+
+var fileHandlerType = reflect.TypeOf(FileHandler(nil))
+
+// parseAuthorization parses the `Authorization` header value.
+func parseAuthorization(s string) (Authorization, error) {
+	var auth Authorization
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, ' ')
+	if i == -1 {
+		auth.Scheme = s
+	} else {
+		auth.Scheme, auth.Credentials = s[:i], strings.TrimSpace(s[i+1:])
+	}
+	if len(auth.Scheme) == 0 {
+		return auth, errors.New("empty authorization scheme")
+	}
+	if strings.EqualFold(auth.Scheme, "Basic") {
+		b, err := base64.StdEncoding.DecodeString(auth.Credentials)
+		if err != nil {
+			return auth, errors.New("invalid basic credentials: " + err.Error())
+		}
+		j := bytes.IndexByte(b, ':')
+		if j == -1 {
+			return auth, errors.New("invalid basic credentials: missing `:`")
+		}
+		auth.User, auth.Password = string(b[:j]), string(b[j+1:])
+	}
+	return auth, nil
+}
+
 type (
 	KV interface {
 		Get(k string) (v string, found bool)
 	}
+	// KeysKV is a KV which can list its keys, e.g. `Map`,
+	// it is required by the catch-all `map[string]string` path param.
+	KeysKV interface {
+		KV
+		Keys() []string
+	}
 	Map map[string]string
 )
 
+// kvToMap copies all the params of kv to a map.
+func kvToMap(kv KeysKV) map[string]string {
+	keys := kv.Keys()
+	m := make(map[string]string, len(keys))
+	for _, k := range keys {
+		m[k], _ = kv.Get(k)
+	}
+	return m
+}
+
 func (m Map) Get(k string) (string, bool) {
 	v, found := m[k]
 	return v, found
 }
+
+// GetInt gets the value as int, it reports false if the key is not found or the value is not an integer.
+func (m Map) GetInt(k string) (int, bool) {
+	v, found := m[k]
+	if !found {
+		return 0, false
+	}
+	i, err := strconv.Atoi(v)
+	return i, err == nil
+}
+
+// GetMulti gets the comma separated value as a slice, e.g. `a,b` is `[]string{"a", "b"}`.
+func (m Map) GetMulti(k string) ([]string, bool) {
+	v, found := m[k]
+	if !found {
+		return nil, false
+	}
+	return strings.Split(v, ","), true
+}
+
+// Keys returns the sorted keys.
+func (m Map) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,6 +1,10 @@
 package apiware
 
 import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -16,6 +20,90 @@ func TestInterfaceToSnake(t *testing.T) {
 	}
 }
 
+func TestParamNameFuncs(t *testing.T) {
+	cases := []struct {
+		fn   ParamNameFunc
+		in   string
+		want string
+	}{
+		{AsIs, "UserID", "UserID"},
+		{ToCamel, "UserName", "userName"},
+		{ToCamel, "UserID", "userID"},
+		{ToCamel, "ID", "id"},
+		{ToCamel, "HTTPServer", "httpServer"},
+		{ToCamel, "page", "page"},
+		{ToKebab, "UserName", "user-name"},
+		{ToKebab, "UserID", "user-id"},
+		{ToKebab, "HTTPServer", "http-server"},
+		{ToKebab, "Page2Size", "page2-size"},
+	}
+	for _, c := range cases {
+		if got := c.fn(c.in); got != c.want {
+			t.Errorf("%q: got %q, want %q", c.in, got, c.want)
+		}
+	}
+
+	type kebabParams struct {
+		PageSize int `param:"in(query)"`
+	}
+	m, err := NewParamsAPI(new(kebabParams), ToKebab, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/?page-size=20", nil)
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.(*kebabParams).PageSize != 20 {
+		t.Fatalf("wrong value %d", v.(*kebabParams).PageSize)
+	}
+}
+
+func TestBodyJSONUseNumber(t *testing.T) {
+	type numberParams struct {
+		Body map[string]interface{} `param:"in(body)"`
+	}
+	m, err := NewParamsAPI(new(numberParams), nil, BodyJSONUseNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"id":9007199254740993,"price":1.5}`))
+	v, err := m.BindNew(req, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := v.(*numberParams).Body
+	if id, ok := body["id"].(json.Number); !ok || id.String() != "9007199254740993" {
+		t.Fatalf("wrong value %#v", body["id"])
+	}
+	if price, ok := body["price"].(json.Number); !ok || price.String() != "1.5" {
+		t.Fatalf("wrong value %#v", body["price"])
+	}
+}
+
+func TestMapAccessors(t *testing.T) {
+	m := Map{"id": "42", "name": "x", "tags": "a,b,c"}
+	if i, ok := m.GetInt("id"); !ok || i != 42 {
+		t.Fatal("wrong value", i, ok)
+	}
+	if _, ok := m.GetInt("name"); ok {
+		t.Fatal("should not be an integer")
+	}
+	if _, ok := m.GetInt("none"); ok {
+		t.Fatal("should not be found")
+	}
+	if s, ok := m.GetMulti("tags"); !ok || !reflect.DeepEqual(s, []string{"a", "b", "c"}) {
+		t.Fatal("wrong value", s, ok)
+	}
+	if _, ok := m.GetMulti("none"); ok {
+		t.Fatal("should not be found")
+	}
+	if keys := m.Keys(); !reflect.DeepEqual(keys, []string{"id", "name", "tags"}) {
+		t.Fatal("wrong keys", keys)
+	}
+}
+
 func TestSnakeToUpperCamel(t *testing.T) {
 	if s := snakeToUpperCamel("table_name"); s != "TableName" {
 		t.Fatal("wrong string", s)